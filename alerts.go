@@ -0,0 +1,470 @@
+package open_meteo_parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultAlertSender is the sender_name of synthesized alerts when the rules
+// do not set one.
+const DefaultAlertSender = "open-meteo-parser"
+
+// defaultRainHours is the accumulation window of heavy rain rules.
+const defaultRainHours = 24
+
+// Alert matches an entry of the alerts array in OpenWeather's One Call
+// response. Start and End are unix times.
+type Alert struct {
+	SenderName  string   `json:"sender_name"`
+	Event       string   `json:"event"`
+	Start       int      `json:"start"`
+	End         int      `json:"end"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// AlertKind is the condition an alert rule checks.
+type AlertKind string
+
+const (
+	// AlertHeavyRain fires when the rain over Hours reaches Threshold mm.
+	// Forecasts carry the rain of the hour before Dt, so each entry counts
+	// for its spacing: an hourly list is summed as is and a 3-hourly one
+	// has each hour tripled.
+	AlertHeavyRain AlertKind = "heavy_rain"
+	// AlertWindGust fires when gusts reach Threshold km/h.
+	AlertWindGust AlertKind = "wind_gust"
+	// AlertHeatIndex fires when the NWS heat index reaches Threshold °C.
+	// Forecasts without humidity have no heat index and never fire it.
+	AlertHeatIndex AlertKind = "heat_index"
+	// AlertFrost fires when the temperature falls to Threshold °C, 0 by
+	// default. Forecasts without a temperature never fire it.
+	AlertFrost AlertKind = "frost"
+	// AlertThunderstorm fires on thunderstorm weather codes.
+	AlertThunderstorm AlertKind = "thunderstorm"
+	// AlertAirQuality fires when the US AQI reaches Category, or Threshold
+	// when no category is set.
+	AlertAirQuality AlertKind = "air_quality"
+)
+
+var alertDefaults = map[AlertKind]struct {
+	event string
+	tags  []string
+}{
+	AlertHeavyRain:    {"Heavy rain", []string{"Rain"}},
+	AlertWindGust:     {"Wind gusts", []string{"Wind"}},
+	AlertHeatIndex:    {"Extreme heat", []string{"Extreme high temperature"}},
+	AlertFrost:        {"Frost", []string{"Extreme low temperature"}},
+	AlertThunderstorm: {"Thunderstorms", []string{"Thunderstorm"}},
+	AlertAirQuality:   {"Poor air quality", []string{"Air quality"}},
+}
+
+// US AQI categories by their lower bound.
+var aqiCategories = []struct {
+	name string
+	min  int
+}{
+	{"good", 0},
+	{"moderate", 51},
+	{"unhealthy_for_sensitive_groups", 101},
+	{"unhealthy", 151},
+	{"very_unhealthy", 201},
+	{"hazardous", 301},
+}
+
+// AlertRule is one configurable threshold. Thresholds are in Open-Meteo's
+// native units, so rules are evaluated against forecasts before InUnits.
+// Event, Description and Tags default per kind.
+type AlertRule struct {
+	Kind        AlertKind `json:"kind" yaml:"kind"`
+	Event       string    `json:"event,omitempty" yaml:"event,omitempty"`
+	Threshold   float64   `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Hours       int       `json:"hours,omitempty" yaml:"hours,omitempty"`
+	Category    string    `json:"category,omitempty" yaml:"category,omitempty"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// AlertRules synthesizes OpenWeather alerts from forecasts, which
+// Open-Meteo has no feed for. Rules can be written in YAML or JSON:
+//
+//	sender: Example Weather
+//	rules:
+//	  - kind: heavy_rain
+//	    threshold: 50
+//	    hours: 24
+//	  - kind: wind_gust
+//	    threshold: 75
+//	  - kind: air_quality
+//	    category: unhealthy
+type AlertRules struct {
+	Sender string      `json:"sender,omitempty" yaml:"sender,omitempty"`
+	Rules  []AlertRule `json:"rules" yaml:"rules"`
+}
+
+// ParseAlertRules reads rules from YAML or JSON and validates them.
+func ParseAlertRules(data []byte) (*AlertRules, error) {
+	var rules AlertRules
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rules); err != nil {
+			return nil, fmt.Errorf("alert rules: %w", err)
+		}
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&rules); err != nil {
+			return nil, fmt.Errorf("alert rules: %w", err)
+		}
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &rules, nil
+}
+
+// LoadAlertRules reads rules from a YAML or JSON file.
+func LoadAlertRules(path string) (*AlertRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAlertRules(data)
+}
+
+// Validate checks every rule has a known kind and the thresholds it needs.
+func (r AlertRules) Validate() error {
+	for i, rule := range r.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("alert rule %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (r AlertRule) validate() error {
+	if _, ok := alertDefaults[r.Kind]; !ok {
+		return fmt.Errorf("unknown kind %q", r.Kind)
+	}
+
+	if r.Hours < 0 {
+		return fmt.Errorf("hours %d is negative", r.Hours)
+	}
+
+	switch r.Kind {
+	case AlertHeavyRain, AlertWindGust, AlertHeatIndex:
+		if r.Threshold <= 0 {
+			return fmt.Errorf("%s needs a positive threshold", r.Kind)
+		}
+	case AlertAirQuality:
+		if r.Category == "" && r.Threshold <= 0 {
+			return fmt.Errorf("%s needs a category or threshold", r.Kind)
+		}
+		if r.Category != "" {
+			if _, ok := aqiCategoryMin(r.Category); !ok {
+				return fmt.Errorf("unknown AQI category %q", r.Category)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Evaluate returns the alerts the forecasts and air quality entries
+// trigger, ordered by start time. Consecutive matching entries make one
+// alert, which ends one step after the last of them.
+func (r AlertRules) Evaluate(forecasts []Forecast, aqi []AQI) []Alert {
+	forecasts = append([]Forecast(nil), forecasts...)
+	sort.SliceStable(forecasts, func(i, j int) bool { return forecasts[i].Dt < forecasts[j].Dt })
+
+	aqi = append([]AQI(nil), aqi...)
+	sort.SliceStable(aqi, func(i, j int) bool { return aqi[i].Dt < aqi[j].Dt })
+
+	times := make([]int, len(forecasts))
+	for i, f := range forecasts {
+		times[i] = f.Dt
+	}
+
+	aqiTimes := make([]int, len(aqi))
+	for i, a := range aqi {
+		aqiTimes[i] = a.Dt
+	}
+
+	sender := r.Sender
+	if sender == "" {
+		sender = DefaultAlertSender
+	}
+
+	alerts := []Alert{}
+	for _, rule := range r.Rules {
+		if rule.Kind == AlertAirQuality {
+			matched, values := rule.matchAQI(aqi)
+			alerts = append(alerts, rule.alerts(sender, aqiTimes, matched, values)...)
+			continue
+		}
+
+		matched, values := rule.matchForecasts(forecasts)
+		alerts = append(alerts, rule.alerts(sender, times, matched, values)...)
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].Start < alerts[j].Start })
+
+	return alerts
+}
+
+// matchForecasts marks the entries the rule fires on, with the value
+// compared against the threshold.
+func (r AlertRule) matchForecasts(forecasts []Forecast) ([]bool, []float64) {
+	matched := make([]bool, len(forecasts))
+	values := make([]float64, len(forecasts))
+
+	switch r.Kind {
+	case AlertHeavyRain:
+		hours := r.Hours
+		if hours == 0 {
+			hours = defaultRainHours
+		}
+		window := hours * int(time.Hour/time.Second)
+
+		times := make([]int, len(forecasts))
+		for i, f := range forecasts {
+			times[i] = f.Dt
+		}
+
+		for i := range forecasts {
+			var total float64
+			j := i
+			for ; j < len(forecasts) && forecasts[j].Dt < forecasts[i].Dt+window; j++ {
+				if _, ok := forecasts[j].sources["rain.3h"]; ok {
+					total += forecasts[j].Rain.ThreeH * float64(stepAt(times, j)) / float64(time.Hour/time.Second)
+				}
+			}
+
+			if total < r.Threshold {
+				continue
+			}
+
+			for k := i; k < j; k++ {
+				matched[k] = true
+				values[k] = math.Max(values[k], total)
+			}
+		}
+	case AlertWindGust:
+		for i, f := range forecasts {
+			values[i] = f.Wind.Gust
+			matched[i] = f.Wind.Gust >= r.Threshold
+		}
+	case AlertHeatIndex:
+		for i, f := range forecasts {
+			if _, ok := f.sources["derived.heat_index"]; !ok {
+				continue
+			}
+			values[i] = f.Derived.HeatIndex
+			matched[i] = values[i] >= r.Threshold
+		}
+	case AlertFrost:
+		for i, f := range forecasts {
+			if _, ok := f.sources["main.temp"]; !ok {
+				continue
+			}
+			values[i] = f.Main.Temp
+			matched[i] = f.Main.Temp <= r.Threshold
+		}
+	case AlertThunderstorm:
+		for i, f := range forecasts {
+			for _, w := range f.Weather {
+				if w.ID >= 200 && w.ID < 300 {
+					matched[i] = true
+				}
+			}
+		}
+	}
+
+	return matched, values
+}
+
+func (r AlertRule) matchAQI(aqi []AQI) ([]bool, []float64) {
+	matched := make([]bool, len(aqi))
+	values := make([]float64, len(aqi))
+
+	threshold := int(math.Ceil(r.Threshold))
+	if r.Category != "" {
+		threshold, _ = aqiCategoryMin(r.Category)
+	}
+
+	for i, a := range aqi {
+		values[i] = float64(a.Main.Aqi)
+		matched[i] = a.Main.Aqi >= threshold
+	}
+
+	return matched, values
+}
+
+// alerts turns runs of matched entries into alerts.
+func (r AlertRule) alerts(sender string, times []int, matched []bool, values []float64) []Alert {
+	defaults := alertDefaults[r.Kind]
+
+	event := r.Event
+	if event == "" {
+		event = defaults.event
+	}
+
+	tags := r.Tags
+	if len(tags) == 0 {
+		tags = defaults.tags
+	}
+
+	var alerts []Alert
+	for i := 0; i < len(times); i++ {
+		if !matched[i] {
+			continue
+		}
+
+		j := i
+		peak := values[i]
+		for j+1 < len(times) && matched[j+1] {
+			j++
+			peak = r.worse(peak, values[j])
+		}
+
+		alerts = append(alerts, Alert{
+			SenderName:  sender,
+			Event:       event,
+			Start:       times[i],
+			End:         times[j] + stepAt(times, j),
+			Description: r.describe(peak),
+			Tags:        append([]string(nil), tags...),
+		})
+
+		i = j
+	}
+
+	return alerts
+}
+
+// worse returns the more severe of two values: the lowest for frost, the
+// highest otherwise.
+func (r AlertRule) worse(a, b float64) float64 {
+	if r.Kind == AlertFrost {
+		return math.Min(a, b)
+	}
+
+	return math.Max(a, b)
+}
+
+func (r AlertRule) describe(peak float64) string {
+	if r.Description != "" {
+		return r.Description
+	}
+
+	switch r.Kind {
+	case AlertHeavyRain:
+		hours := r.Hours
+		if hours == 0 {
+			hours = defaultRainHours
+		}
+		return fmt.Sprintf("Rain totals of up to %.1f mm in %d hours expected.", peak, hours)
+	case AlertWindGust:
+		return fmt.Sprintf("Wind gusts of up to %.0f km/h expected.", peak)
+	case AlertHeatIndex:
+		return fmt.Sprintf("Heat index of up to %.0f °C expected.", peak)
+	case AlertFrost:
+		return fmt.Sprintf("Temperatures down to %.1f °C expected.", peak)
+	case AlertThunderstorm:
+		return "Thunderstorms expected."
+	case AlertAirQuality:
+		aqi := int(peak)
+		return fmt.Sprintf("Air quality index of up to %d (%s) expected.", aqi, strings.ReplaceAll(aqiCategory(aqi), "_", " "))
+	}
+
+	return ""
+}
+
+func (r AlertRules) needsAQI() bool {
+	for _, rule := range r.Rules {
+		if rule.Kind == AlertAirQuality {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stepAt is the spacing after entry i, taken from the previous entry for the
+// last one and an hour when there is a single entry.
+func stepAt(times []int, i int) int {
+	switch {
+	case i+1 < len(times):
+		return times[i+1] - times[i]
+	case i > 0:
+		return times[i] - times[i-1]
+	default:
+		return int(time.Hour / time.Second)
+	}
+}
+
+func aqiCategoryMin(name string) (int, bool) {
+	name = strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(name))
+	for _, c := range aqiCategories {
+		if c.name == name {
+			return c.min, true
+		}
+	}
+
+	return 0, false
+}
+
+func aqiCategory(aqi int) string {
+	name := aqiCategories[0].name
+	for _, c := range aqiCategories {
+		if aqi >= c.min {
+			name = c.name
+		}
+	}
+
+	return name
+}
+
+// GetOpenWeatherAlerts evaluates the rules against the hourly forecast for
+// hours from startTime, and the air quality forecast when a rule needs it.
+func (p Parser) GetOpenWeatherAlerts(latitude, longitude float64, startTime time.Time, hours int, rules *AlertRules) ([]Alert, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+
+	list, err := p.GetOpenWeatherForecastList(latitude, longitude, startTime, time.Hour, hours)
+	if err != nil {
+		return nil, err
+	}
+
+	var aqi []AQI
+	if rules.needsAQI() {
+		resp, err := p.GetOpenWeatherAQIForecast(latitude, longitude, startTime)
+		if err != nil {
+			return nil, err
+		}
+
+		end := int(startTime.Add(time.Duration(hours) * time.Hour).Unix())
+		for _, a := range resp.List {
+			if a.Dt < end {
+				aqi = append(aqi, a)
+			}
+		}
+	}
+
+	return rules.Evaluate(list.List, aqi), nil
+}
@@ -0,0 +1,202 @@
+package open_meteo_parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAlertRules(t *testing.T) {
+	yamlRules := `
+sender: Example Weather
+rules:
+  - kind: heavy_rain
+    threshold: 50
+    hours: 12
+  - kind: air_quality
+    category: Unhealthy for sensitive groups
+    tags: [Smog]
+`
+	jsonRules := `{
+	"sender": "Example Weather",
+	"rules": [
+		{"kind": "heavy_rain", "threshold": 50, "hours": 12},
+		{"kind": "air_quality", "category": "Unhealthy for sensitive groups", "tags": ["Smog"]}
+	]
+}`
+
+	want := &AlertRules{
+		Sender: "Example Weather",
+		Rules: []AlertRule{
+			{Kind: AlertHeavyRain, Threshold: 50, Hours: 12},
+			{Kind: AlertAirQuality, Category: "Unhealthy for sensitive groups", Tags: []string{"Smog"}},
+		},
+	}
+
+	for name, data := range map[string]string{"yaml": yamlRules, "json": jsonRules} {
+		got, err := ParseAlertRules([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(yamlRules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := LoadAlertRules(path); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadAlertRules = %+v, %v", got, err)
+	}
+
+	invalid := []string{
+		"rules:\n  - kind: tornado\n",
+		"rules:\n  - kind: wind_gust\n",
+		"rules:\n  - kind: air_quality\n    category: awful\n",
+		"rules:\n  - kind: frost\n    treshold: -2\n",
+		`{"rules": [{"kind": "heavy_rain", "threshold": 10, "hours": -1}]}`,
+	}
+
+	for _, data := range invalid {
+		if _, err := ParseAlertRules([]byte(data)); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+func TestAlertRules_Evaluate(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour int) int {
+		return int(start.Add(time.Duration(hour) * time.Hour).Unix())
+	}
+
+	forecasts := make([]Forecast, 8)
+	for i := range forecasts {
+		forecasts[i] = Forecast{
+			Dt:      at(i),
+			Main:    Main{Temp: 20, Humidity: 50},
+			Weather: []Weather{{ID: 800}},
+		}
+	}
+
+	forecasts[1].Rain.ThreeH = 6
+	forecasts[2].Rain.ThreeH = 8
+	forecasts[3].Wind.Gust = 80
+	forecasts[4].Wind.Gust = 95
+	forecasts[5].Main = Main{Temp: 35, Humidity: 70}
+	forecasts[6].Main.Temp = -1.5
+	forecasts[7].Weather[0].ID = 211
+
+	for i := range forecasts {
+		forecasts[i].sources = fieldSources{"main.temp": ResolutionHourly, "main.humidity": ResolutionHourly, "rain.3h": ResolutionHourly}
+		forecasts[i].derive(forecasts[i].sources)
+	}
+
+	aqi := []AQI{{Dt: at(0)}, {Dt: at(1)}, {Dt: at(2)}}
+	aqi[0].Main.Aqi = 120
+	aqi[1].Main.Aqi = 160
+
+	rules := AlertRules{Rules: []AlertRule{
+		{Kind: AlertHeavyRain, Threshold: 12, Hours: 2},
+		{Kind: AlertWindGust, Threshold: 75},
+		{Kind: AlertHeatIndex, Threshold: 41},
+		{Kind: AlertFrost},
+		{Kind: AlertThunderstorm, Event: "Severe thunderstorm", Description: "Take shelter."},
+		{Kind: AlertAirQuality, Category: "unhealthy_for_sensitive_groups"},
+	}}
+
+	want := []Alert{
+		{DefaultAlertSender, "Poor air quality", at(0), at(2), "Air quality index of up to 160 (unhealthy) expected.", []string{"Air quality"}},
+		{DefaultAlertSender, "Heavy rain", at(1), at(3), "Rain totals of up to 14.0 mm in 2 hours expected.", []string{"Rain"}},
+		{DefaultAlertSender, "Wind gusts", at(3), at(5), "Wind gusts of up to 95 km/h expected.", []string{"Wind"}},
+		{DefaultAlertSender, "Extreme heat", at(5), at(6), "Heat index of up to 50 °C expected.", []string{"Extreme high temperature"}},
+		{DefaultAlertSender, "Frost", at(6), at(7), "Temperatures down to -1.5 °C expected.", []string{"Extreme low temperature"}},
+		{DefaultAlertSender, "Severe thunderstorm", at(7), at(8), "Take shelter.", []string{"Thunderstorm"}},
+	}
+
+	got := rules.Evaluate(forecasts, aqi)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	if got := (AlertRules{}).Evaluate(forecasts, aqi); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil list, got %#v", got)
+	}
+}
+
+func TestAlertRules_EvaluateMissing(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour int) int {
+		return int(start.Add(time.Duration(hour) * time.Hour).Unix())
+	}
+
+	// a 3-hourly list with 2 mm in the hour before each entry, a hot entry
+	// without humidity and one without any temperature
+	forecasts := make([]Forecast, 3)
+	for i := range forecasts {
+		forecasts[i] = Forecast{
+			Dt:      at(3 * i),
+			Main:    Main{Temp: 35},
+			Rain:    Rain{ThreeH: 2},
+			Weather: []Weather{{ID: 800}},
+			sources: fieldSources{"main.temp": ResolutionHourly, "rain.3h": ResolutionHourly},
+		}
+	}
+	forecasts[2].Main.Temp = 0
+	delete(forecasts[2].sources, "main.temp")
+
+	for i := range forecasts {
+		forecasts[i].derive(forecasts[i].sources)
+	}
+
+	rules := AlertRules{Rules: []AlertRule{
+		{Kind: AlertHeavyRain, Threshold: 12, Hours: 9},
+		{Kind: AlertHeatIndex, Threshold: 30},
+		{Kind: AlertFrost},
+	}}
+
+	want := []Alert{
+		{DefaultAlertSender, "Heavy rain", at(0), at(9), "Rain totals of up to 18.0 mm in 9 hours expected.", []string{"Rain"}},
+	}
+
+	if got := rules.Evaluate(forecasts, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParser_GetOpenWeatherAlerts(t *testing.T) {
+	p := NewParser("xxx", "https://ddd.cloudfront.net")
+	p.om = &fakeOpenMeteo{}
+	p.now = fakeNow
+
+	rules := &AlertRules{Sender: "Test", Rules: []AlertRule{
+		{Kind: AlertWindGust, Threshold: 21},
+		{Kind: AlertAirQuality, Threshold: 45},
+	}}
+
+	start := fakeNow()
+	alerts, err := p.GetOpenWeatherAlerts(-8.68, 115.2, start, 3, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hour := int(time.Hour / time.Second)
+	want := []Alert{
+		{"Test", "Wind gusts", int(start.Unix()) + hour, int(start.Unix()) + 3*hour, "Wind gusts of up to 22 km/h expected.", []string{"Wind"}},
+		{"Test", "Poor air quality", int(start.Unix()) + hour, int(start.Unix()) + 2*hour, "Air quality index of up to 45 (good) expected.", []string{"Air quality"}},
+	}
+
+	if !reflect.DeepEqual(alerts, want) {
+		t.Errorf("got %+v\nwant %+v", alerts, want)
+	}
+
+	if _, err := p.GetOpenWeatherAlerts(-8.68, 115.2, start, 3, &AlertRules{Rules: []AlertRule{{Kind: "hail"}}}); err == nil {
+		t.Error("expected error for an invalid rule")
+	}
+}
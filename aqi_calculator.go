@@ -1,7 +1,5 @@
 package open_meteo_parser
 
-import "fmt"
-
 func CalculateAQI(pm25, pm10, o3, no2, so2, co float64) int {
 	pm25AQI := calculatePM25AQI(pm25)
 	pm10AQI := calculatePM10AQI(pm10)
@@ -10,13 +8,6 @@ func CalculateAQI(pm25, pm10, o3, no2, so2, co float64) int {
 	so2AQI := calculateSO2AQI(so2)
 	coAQI := calculateCOAQI(co)
 
-	fmt.Println("PM25 AQI: ", pm25AQI)
-	fmt.Println("PM10 AQI: ", pm10AQI)
-	fmt.Println("O3 AQI: ", o3AQI)
-	fmt.Println("NO2 AQI: ", no2AQI)
-	fmt.Println("SO2 AQI: ", so2AQI)
-	fmt.Println("CO AQI: ", coAQI)
-
 	return int(max(pm25AQI, pm10AQI, o3AQI, no2AQI, so2AQI, coAQI))
 }
 
@@ -0,0 +1,217 @@
+package open_meteo_parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+const (
+	defaultBatchChunkSize = 50
+	defaultBatchWorkers   = 4
+)
+
+type BatchLocation struct {
+	Latitude  float64
+	Longitude float64
+	StartTime time.Time
+}
+
+type BatchResult struct {
+	Location BatchLocation
+	Forecast *Forecast
+	Err      error
+}
+
+type batchConfig struct {
+	chunkSize int
+	workers   int
+}
+
+func defaultBatchConfig() batchConfig {
+	return batchConfig{
+		chunkSize: defaultBatchChunkSize,
+		workers:   defaultBatchWorkers,
+	}
+}
+
+// SetBatchOptions configures how many locations are sent in a single
+// multi-location request and how many requests may run concurrently.
+// Values below 1 fall back to the defaults.
+func (p *Parser) SetBatchOptions(chunkSize, workers int) *Parser {
+	p.batch = defaultBatchConfig()
+
+	if chunkSize > 0 {
+		p.batch.chunkSize = chunkSize
+	}
+
+	if workers > 0 {
+		p.batch.workers = workers
+	}
+
+	return p
+}
+
+// GetOpenWeatherForecastBatch fetches forecasts for many locations using
+// Open-Meteo's comma-separated coordinate lists. Results are returned in the
+// same order as locations, each carrying either a forecast or its own error.
+// The UV index and ensemble Pop, when enabled, still cost a request per
+// location each.
+func (p Parser) GetOpenWeatherForecastBatch(locations []BatchLocation) []BatchResult {
+	results := make([]BatchResult, len(locations))
+
+	var valid []int
+	for i, loc := range locations {
+		results[i].Location = loc
+
+		if _, err := p.forecastParams(loc.Latitude, loc.Longitude); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		valid = append(valid, i)
+	}
+
+	cfg := p.batch
+	if cfg.chunkSize < 1 || cfg.workers < 1 {
+		cfg = defaultBatchConfig()
+	}
+
+	chunks := make(chan []int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < cfg.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				p.processForecastChunk(locations, chunk, results)
+			}
+		}()
+	}
+
+	for start := 0; start < len(valid); start += cfg.chunkSize {
+		end := start + cfg.chunkSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		chunks <- valid[start:end]
+	}
+
+	close(chunks)
+	wg.Wait()
+
+	return results
+}
+
+func (p Parser) processForecastChunk(locations []BatchLocation, chunk []int, results []BatchResult) {
+	lats := make([]float64, len(chunk))
+	lons := make([]float64, len(chunk))
+	for i, idx := range chunk {
+		lats[i] = locations[idx].Latitude
+		lons[i] = locations[idx].Longitude
+	}
+
+	responses, err := p.fetchForecasts(lats, lons)
+	if err != nil {
+		for _, idx := range chunk {
+			results[idx].Err = err
+		}
+		return
+	}
+
+	for i, idx := range chunk {
+		loc := locations[idx]
+		prepareResponse(&responses[i])
+		results[idx].Forecast, results[idx].Err = p.forecastFromResponse(&responses[i], loc.StartTime)
+		if results[idx].Err == nil {
+			p.completeForecast(results[idx].Forecast, loc.Latitude, loc.Longitude, loc.StartTime)
+		}
+	}
+}
+
+func (p Parser) fetchForecasts(lats, lons []float64) ([]pom.ForecastResponse, error) {
+	return p.fetchForecastsContext(context.Background(), lats, lons)
+}
+
+func (p Parser) fetchForecastsContext(ctx context.Context, lats, lons []float64) ([]pom.ForecastResponse, error) {
+	params, err := p.forecastParams(lats[0], lons[0])
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := url.ParseQuery(params.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	query.Set("latitude", joinFloats(lats))
+	query.Set("longitude", joinFloats(lons))
+
+	var data []byte
+	done := p.start(OperationFetchForecast)
+	err = p.callContext(ctx, requestWeight(query), func() (err error) {
+		data, err = fetchContext(ctx, p.callApi, p.config.GetForecastURL()+"?"+query.Encode())
+		return err
+	})
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := decodeForecastResponses(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(responses) != len(lats) {
+		return nil, fmt.Errorf("%w: expected %d forecasts, got %d", ErrDecodeResponse, len(lats), len(responses))
+	}
+
+	return responses, nil
+}
+
+// decodeForecastResponses accepts both the array returned for multiple
+// locations and the single object Open-Meteo returns for one location.
+func decodeForecastResponses(data []byte) ([]pom.ForecastResponse, error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) > 0 && data[0] == '[' {
+		var raw []json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+		}
+
+		responses := make([]pom.ForecastResponse, len(raw))
+		for i := range raw {
+			if err := decodeForecastResponse(raw[i], &responses[i]); err != nil {
+				return nil, err
+			}
+		}
+		return responses, nil
+	}
+
+	var resp pom.ForecastResponse
+	if err := decodeForecastResponse(data, &resp); err != nil {
+		return nil, err
+	}
+
+	return []pom.ForecastResponse{resp}, nil
+}
+
+func joinFloats(values []float64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.FormatFloat(v, 'f', 6, 64)
+	}
+
+	return strings.Join(parts, ",")
+}
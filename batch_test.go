@@ -0,0 +1,122 @@
+package open_meteo_parser
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	go_http "github.com/saktibimantara/go-http"
+)
+
+func TestParser_GetOpenWeatherForecastBatch(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 1, 10, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		locations  []BatchLocation
+		chunkSize  int
+		wantCalls  int
+		wantErrIdx []int
+	}{
+		{
+			name: "Test single chunk",
+			locations: []BatchLocation{
+				{Latitude: -8.68, Longitude: 115.2, StartTime: startTime},
+				{Latitude: -8.5, Longitude: 115.3, StartTime: startTime},
+			},
+			chunkSize: 10,
+			wantCalls: 1,
+		},
+		{
+			name: "Test chunked with invalid location",
+			locations: []BatchLocation{
+				{Latitude: -8.68, Longitude: 115.2, StartTime: startTime},
+				{Latitude: 91, Longitude: 115.2, StartTime: startTime},
+				{Latitude: -8.5, Longitude: 115.3, StartTime: startTime},
+				{Latitude: -8.4, Longitude: 115.1, StartTime: startTime},
+			},
+			chunkSize:  2,
+			wantCalls:  2,
+			wantErrIdx: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, caller := newFakeParser("")
+			p.SetBatchOptions(tt.chunkSize, 2)
+			caller.respond = func(rawURL string) (*go_http.Response, error) {
+				u, err := url.Parse(rawURL)
+				if err != nil {
+					return nil, err
+				}
+				n := len(strings.Split(u.Query().Get("latitude"), ","))
+				return &go_http.Response{Code: 200, Data: repeatJSON(fakeForecastJSON, n)}, nil
+			}
+
+			results := p.GetOpenWeatherForecastBatch(tt.locations)
+
+			if len(results) != len(tt.locations) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.locations))
+			}
+
+			if got := len(caller.calls()); got != tt.wantCalls {
+				t.Errorf("got %d upstream calls, want %d", got, tt.wantCalls)
+			}
+
+			for i, res := range results {
+				wantErr := false
+				for _, idx := range tt.wantErrIdx {
+					wantErr = wantErr || idx == i
+				}
+
+				if (res.Err != nil) != wantErr {
+					t.Errorf("result %d: err = %v, wantErr %v", i, res.Err, wantErr)
+					continue
+				}
+
+				if wantErr {
+					continue
+				}
+
+				if res.Location != tt.locations[i] {
+					t.Errorf("result %d: location %v, want %v", i, res.Location, tt.locations[i])
+				}
+
+				if res.Forecast == nil || res.Forecast.Main.Temp != 27.5 {
+					t.Errorf("result %d: unexpected forecast %+v", i, res.Forecast)
+				}
+			}
+		})
+	}
+}
+
+func TestParser_GetOpenWeatherForecastBatchUV(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	p, caller := newFakeParser("")
+	p.SetUVIndex(true)
+	caller.respond = func(rawURL string) (*go_http.Response, error) {
+		if strings.Contains(rawURL, "air-quality") {
+			return &go_http.Response{Code: 200, Data: []byte(fakeAQIJSON)}, nil
+		}
+		return &go_http.Response{Code: 200, Data: repeatJSON(fakeForecastJSON, 2)}, nil
+	}
+
+	results := p.GetOpenWeatherForecastBatch([]BatchLocation{
+		{Latitude: -8.68, Longitude: 115.2, StartTime: startTime},
+		{Latitude: -8.5, Longitude: 115.3, StartTime: startTime},
+	})
+
+	// the UV index is filled as for a single location
+	for i, res := range results {
+		if res.Err != nil {
+			t.Fatalf("result %d: %v", i, res.Err)
+		}
+
+		if _, ok := res.Forecast.sources["uvi"]; !ok || *res.Forecast.Uvi != 0.5 {
+			t.Errorf("result %d: Uvi = %v", i, res.Forecast.Uvi)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package open_meteo_parser
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrCityNotFound mirrors OpenWeather's response for an unknown q parameter.
+var ErrCityNotFound = &Error{Cod: "404", Message: "city not found"}
+
+// ParseCityQuery splits an OpenWeather q value ("London", "London,GB" or
+// "London,ENG,GB") into the city name and country code. The state code is
+// ignored.
+func ParseCityQuery(q string) (name, country string) {
+	parts := strings.Split(q, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	name = parts[0]
+	if len(parts) > 1 {
+		country = parts[len(parts)-1]
+	}
+
+	return name, country
+}
+
+// ResolveCity returns the best match for an OpenWeather q value. When several
+// places share the name, an exact name match wins, then the most populous.
+func (p Parser) ResolveCity(q string) (*Place, error) {
+	name, country := ParseCityQuery(q)
+	if name == "" {
+		return nil, ErrCityNotFound
+	}
+
+	geocoder := p.cityGeocoder
+	if geocoder == nil {
+		geocoder = newOpenMeteoGeocoder(p.callApi)
+	}
+
+	places, err := geocoder.Geocode(name, country)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(places) == 0 {
+		return nil, ErrCityNotFound
+	}
+
+	sort.SliceStable(places, func(i, j int) bool {
+		iExact := strings.EqualFold(places[i].Name, name)
+		jExact := strings.EqualFold(places[j].Name, name)
+		if iExact != jExact {
+			return iExact
+		}
+
+		return places[i].Population > places[j].Population
+	})
+
+	return &places[0], nil
+}
+
+func (p Parser) GetOpenWeatherForecastByCity(q string, startTime time.Time) (*Forecast, error) {
+	place, err := p.ResolveCity(q)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := p.GetOpenWeatherForecast(place.Latitude, place.Longitude, startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	setPlace(forecast, place)
+
+	return forecast, nil
+}
+
+func (p Parser) GetOpenWeatherAQIByCity(q string, startTime time.Time) (*AQI, error) {
+	place, err := p.ResolveCity(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.GetOpenWeatherAQI(place.Latitude, place.Longitude, startTime)
+}
@@ -0,0 +1,84 @@
+package open_meteo_parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	go_http "github.com/saktibimantara/go-http"
+)
+
+const testCityDump = testGeoNamesDump +
+	"4951788\tSpringfield\tSpringfield\t\t42.10148\t-72.58981\tP\tPPLA2\tUS\t\tMA\t\t\t\t155929\t\t21\tAmerica/New_York\t2023-01-01\n" +
+	"4409896\tSpringfield\tSpringfield\t\t37.21533\t-93.29824\tP\tPPLA2\tUS\t\tMO\t\t\t\t169176\t\t397\tAmerica/Chicago\t2023-01-01\n"
+
+func TestParser_GetOpenWeatherForecastByCity(t *testing.T) {
+	g, err := NewGeoNamesGeocoder(strings.NewReader(testCityDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		q        string
+		wantID   int
+		wantCode string
+	}{
+		{"Test name and country", "London,GB", 2643743, ""},
+		{"Test name only", "denpasar", 1645528, ""},
+		{"Test ambiguous prefers most populous", "Springfield,US", 4409896, ""},
+		{"Test wrong country", "London,US", 0, "404"},
+		{"Test unknown city", "Atlantis", 0, "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser("xxx", "https://ddd.cloudfront.net").SetGeocoder(g)
+			p.now = fakeNow
+			p.om = &fakeOpenMeteo{}
+
+			forecast, err := p.GetOpenWeatherForecastByCity(tt.q, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC))
+
+			if tt.wantCode != "" {
+				var owErr *Error
+				if !errors.As(err, &owErr) || owErr.Cod != tt.wantCode {
+					t.Fatalf("err = %v, want cod %s", err, tt.wantCode)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if forecast.City.ID != tt.wantID {
+				t.Errorf("City.ID = %d, want %d", forecast.City.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestOpenMeteoGeocoder_Geocode(t *testing.T) {
+	caller := &fakeCaller{
+		respond: func(url string) (*go_http.Response, error) {
+			return &go_http.Response{Code: 200, Data: []byte(`{"results":[
+				{"id":2643743,"name":"London","latitude":51.50853,"longitude":-0.12574,"country_code":"GB","timezone":"Europe/London","population":8961989},
+				{"id":6058560,"name":"London","latitude":42.98339,"longitude":-81.23304,"country_code":"CA","timezone":"America/Toronto","population":346765}
+			]}`)}, nil
+		},
+	}
+
+	places, err := newOpenMeteoGeocoder(caller).Geocode("London", "CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(places) != 1 || places[0].ID != 6058560 {
+		t.Errorf("unexpected places %+v", places)
+	}
+
+	if calls := caller.calls(); len(calls) != 1 || !strings.Contains(calls[0], "name=London") {
+		t.Errorf("unexpected calls %v", calls)
+	}
+}
@@ -0,0 +1,283 @@
+package open_meteo_parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	go_http "github.com/saktibimantara/go-http"
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// httpCaller is a go_http.CallAPI whose Get applies a timeout and reports
+// non-200 responses as *UpstreamError, keeping the Retry-After header that
+// go_http.Response has no room for.
+type httpCaller struct {
+	*go_http.GoHTTP
+	client *http.Client
+}
+
+func newHTTPCaller(timeout time.Duration) *httpCaller {
+	return &httpCaller{
+		GoHTTP: go_http.New(&go_http.Config{}),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// contextCaller is a go_http.CallAPI whose requests can be cancelled.
+type contextCaller interface {
+	GetContext(ctx context.Context, url string) (*go_http.Response, error)
+}
+
+func (c *httpCaller) Get(url string) (*go_http.Response, error) {
+	return c.GetContext(context.Background(), url)
+}
+
+func (c *httpCaller) GetContext(ctx context.Context, url string) (*go_http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		upstream := newUpstreamError(resp.StatusCode, data)
+		upstream.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, upstream
+	}
+
+	return &go_http.Response{Code: resp.StatusCode, Data: data}, nil
+}
+
+// parseRetryAfter accepts both forms of Retry-After: delay seconds and an
+// HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
+
+// openMeteoClient implements pom.IGoOpenMeteo on top of go-http. Unlike
+// pom.GoOpenMeteo it does not print request URLs to stdout, which would
+// corrupt the output of the command-line tools.
+type openMeteoClient struct {
+	config  pom.IConfig
+	callApi go_http.CallAPI
+}
+
+func newOpenMeteoClient(config pom.IConfig, callApi go_http.CallAPI) *openMeteoClient {
+	return &openMeteoClient{config: config, callApi: callApi}
+}
+
+// apiURL returns the URL of the Open-Meteo API called name, such as
+// "marine", beside the configured forecast API. Open-Meteo names its hosts
+// after the API, so https://api.open-meteo.com gives
+// https://marine-api.open-meteo.com and a customer-api host gives
+// customer-marine-api. Any other host, such as a self-hosted instance, is
+// taken to serve every API itself.
+func (p Parser) apiURL(name, path string) string {
+	base := strings.TrimSuffix(p.config.BaseURL, "/")
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + path
+	}
+
+	label, domain, _ := strings.Cut(u.Host, ".")
+	if label == "api" || strings.HasSuffix(label, "-api") {
+		u.Host = strings.TrimSuffix(label, "api") + name + "-api"
+		if domain != "" {
+			u.Host += "." + domain
+		}
+	}
+
+	return u.String() + path
+}
+
+func (c *openMeteoClient) Forecast(param pom.IForecastParams) (*pom.ForecastResponse, error) {
+	data, err := fetch(c.callApi, c.config.GetForecastURL()+"?"+param.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pom.ForecastResponse
+	if err := decodeForecastResponse(data, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// decodeForecastResponse decodes a forecast response from data.
+// go-open-meteo reads the UTC offset from "utc_offset", which Open-Meteo
+// does not send, so it is taken from "utc_offset_seconds" here. Its series
+// are []float64, which would turn the nulls Open-Meteo sends for values a
+// model lacks into 0, so those are marked as missing.
+func decodeForecastResponse(data []byte, resp *pom.ForecastResponse) error {
+	var extra struct {
+		UTCOffsetSeconds int                        `json:"utc_offset_seconds"`
+		Hourly           map[string]json.RawMessage `json:"hourly"`
+		Minutely15       map[string]json.RawMessage `json:"minutely_15"`
+		Daily            map[string]json.RawMessage `json:"daily"`
+	}
+
+	if err := json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+	}
+
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+	}
+
+	resp.UTCOffsetSeconds = extra.UTCOffsetSeconds
+
+	var err error
+	if resp.Hourly != nil {
+		err = errors.Join(err, markMissingSeries(extra.Hourly, resp.Hourly))
+	}
+	if resp.Minutely15 != nil {
+		err = errors.Join(err, markMissingSeries(extra.Minutely15, resp.Minutely15))
+	}
+	if resp.Daily != nil {
+		err = errors.Join(err, markMissingSeries(extra.Daily, resp.Daily))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+	}
+
+	return nil
+}
+
+// missingWeatherCode marks a weather code Open-Meteo sent as null.
+const missingWeatherCode pom.WeatherCodeResponse = -1
+
+// markMissingSeries marks the values of series, a go-open-meteo series
+// struct decoded from raw, that raw has as null: numbers as NaN and
+// weather codes as missingWeatherCode.
+func markMissingSeries(raw map[string]json.RawMessage, series any) error {
+	v := reflect.ValueOf(series).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		data, ok := raw[name]
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch values := v.Field(i).Interface().(type) {
+		case []float64:
+			err = markNulls(data, values, math.NaN())
+		case []pom.WeatherCodeResponse:
+			err = markNulls(data, values, missingWeatherCode)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// markNulls sets the values that data, the JSON array they were decoded
+// from, has as null to missing.
+func markNulls[T any](data json.RawMessage, values []T, missing T) error {
+	var nullable []*T
+	if err := json.Unmarshal(data, &nullable); err != nil {
+		return err
+	}
+
+	for i, v := range nullable {
+		if v == nil && i < len(values) {
+			values[i] = missing
+		}
+	}
+
+	return nil
+}
+
+func (c *openMeteoClient) GetAQI(param pom.IForecastParams) (*pom.AQIResponse, error) {
+	data, err := fetch(c.callApi, c.config.GetAirQualityURL()+"?"+param.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pom.AQIResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+	}
+
+	if err := markMissingPollen(data, &resp); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+	}
+
+	return &resp, nil
+}
+
+func fetch(callApi go_http.CallAPI, url string) ([]byte, error) {
+	return fetchContext(context.Background(), callApi, url)
+}
+
+// fetchContext is fetch, cancelling the request when ctx is done if callApi
+// supports it.
+func fetchContext(ctx context.Context, callApi go_http.CallAPI, url string) ([]byte, error) {
+	var callResp *go_http.Response
+	var err error
+	if cc, ok := callApi.(contextCaller); ok {
+		callResp, err = cc.GetContext(ctx, url)
+	} else {
+		callResp, err = callApi.Get(url)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if callResp.Code != 200 {
+		return nil, newUpstreamError(callResp.Code, callResp.Data)
+	}
+
+	return callResp.Data, nil
+}
+
+func fetchJSON(callApi go_http.CallAPI, url string, v any) error {
+	data, err := fetch(callApi, url)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package open_meteo_parser
+
+import "testing"
+
+func TestParser_APIURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"https://api.open-meteo.com", "https://marine-api.open-meteo.com/v1/marine"},
+		{"https://customer-api.open-meteo.com", "https://customer-marine-api.open-meteo.com/v1/marine"},
+		{"http://localhost:8080/", "http://localhost:8080/v1/marine"},
+		{"https://weather.example.com", "https://weather.example.com/v1/marine"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser("xxx", "https://ddd.cloudfront.net")
+		p.config.BaseURL = tt.baseURL
+
+		if got := p.apiURL("marine", marinePath); got != tt.want {
+			t.Errorf("apiURL with base %q = %q, want %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Command omparse queries Open-Meteo forecasts and air quality and prints
+// them as OpenWeather JSON, a table or CSV.
+//
+//	omparse current --lat -8.65 --lon 115.21 --units metric
+//	omparse forecast --q Denpasar,ID --cnt 8 --format table
+//	omparse aqi --lat -8.65 --lon 115.21 --time 2024-05-01T09:00:00+08:00
+//	omparse range --lat -8.65 --lon 115.21 --time now --end 24h --step 1h --format csv
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+)
+
+const usage = `usage: omparse <command> [flags]
+
+commands:
+  current   weather at --time, shaped like /data/2.5/weather
+  forecast  3-hourly forecast from --time, shaped like /data/2.5/forecast
+  aqi       air quality at --time, shaped like /data/2.5/air_pollution
+  range     forecasts from --time to --end every --step
+
+run "omparse <command> -h" for command flags
+`
+
+var errUsage = errors.New("usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, time.Now()); err != nil {
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+
+		fmt.Fprintln(os.Stderr, "omparse:", err)
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	lat, lon  float64
+	q         string
+	time      time.Time
+	units     omp.Units
+	lang      string
+	format    string
+	cnt       int
+	end       string
+	step      time.Duration
+	appid     string
+	hasCoords bool
+}
+
+func run(args []string, stdout io.Writer, now time.Time) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return errUsage
+	}
+
+	cmd := args[0]
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+
+	opts := &options{}
+	var timeFlag, unitsFlag string
+	fs.Float64Var(&opts.lat, "lat", 0, "latitude")
+	fs.Float64Var(&opts.lon, "lon", 0, "longitude")
+	fs.StringVar(&opts.q, "q", "", "city name, e.g. London,GB (instead of --lat/--lon)")
+	fs.StringVar(&timeFlag, "time", "now", "start time: now, RFC 3339 or \"2006-01-02 15:04\" in UTC")
+	fs.StringVar(&unitsFlag, "units", "standard", "standard, metric or imperial")
+	fs.StringVar(&opts.lang, "lang", "en", "language (descriptions are English only)")
+	fs.StringVar(&opts.format, "format", "json", "json, table or csv")
+	fs.StringVar(&opts.appid, "appid", os.Getenv("OMPARSE_APPID"), "API key stored on the parser")
+
+	switch cmd {
+	case "forecast":
+		fs.IntVar(&opts.cnt, "cnt", 40, "number of 3-hour entries")
+	case "range":
+		fs.StringVar(&opts.end, "end", "24h", "end time, or a duration after --time")
+		fs.DurationVar(&opts.step, "step", time.Hour, "interval between entries")
+	case "current", "aqi":
+	case "-h", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return errUsage
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errUsage
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "lat" || f.Name == "lon" {
+			opts.hasCoords = true
+		}
+	})
+
+	if !opts.hasCoords && opts.q == "" {
+		return fmt.Errorf("either --lat/--lon or --q is required")
+	}
+
+	var err error
+	if opts.time, err = parseTime(timeFlag, now); err != nil {
+		return err
+	}
+
+	if opts.units, err = omp.ParseUnits(unitsFlag); err != nil {
+		return err
+	}
+
+	if opts.format != "json" && opts.format != "table" && opts.format != "csv" {
+		return fmt.Errorf("unknown format %q", opts.format)
+	}
+
+	parser := omp.NewParser(opts.appid, "").SetRetryPolicy(omp.DefaultRetryPolicy())
+
+	var out *output
+	switch cmd {
+	case "current":
+		out, err = current(parser, opts)
+	case "forecast":
+		out, err = forecast(parser, opts, opts.time, 3*time.Hour, opts.cnt)
+	case "range":
+		out, err = forecastRange(parser, opts)
+	case "aqi":
+		out, err = aqi(parser, opts)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return out.write(stdout, opts.format)
+}
+
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" || value == "now" {
+		return now, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse time %q", value)
+}
+
+func resolve(parser *omp.Parser, opts *options) (*omp.Place, error) {
+	if opts.q == "" {
+		return nil, nil
+	}
+
+	place, err := parser.ResolveCity(opts.q)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.lat, opts.lon = place.Latitude, place.Longitude
+
+	return place, nil
+}
+
+func current(parser *omp.Parser, opts *options) (*output, error) {
+	var f *omp.Forecast
+	var err error
+	if opts.q != "" {
+		f, err = parser.GetOpenWeatherForecastByCity(opts.q, opts.time)
+	} else {
+		f, err = parser.GetOpenWeatherForecast(opts.lat, opts.lon, opts.time)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	converted := f.InUnits(opts.units)
+
+	return forecastOutput(omp.NewCurrentWeather(&converted), []omp.Forecast{converted}, opts.units), nil
+}
+
+func forecast(parser *omp.Parser, opts *options, start time.Time, step time.Duration, cnt int) (*output, error) {
+	place, err := resolve(parser, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := parser.GetOpenWeatherForecastList(opts.lat, opts.lon, start, step, cnt)
+	if err != nil {
+		return nil, err
+	}
+
+	if place != nil {
+		resp.City.ID = place.ID
+		resp.City.Name = place.Name
+		resp.City.Country = place.Country
+		resp.City.Population = place.Population
+	}
+
+	for i := range resp.List {
+		resp.List[i] = resp.List[i].InUnits(opts.units)
+		resp.List[i].Main.Location = resp.City.Name
+	}
+
+	return forecastOutput(resp, resp.List, opts.units), nil
+}
+
+func forecastRange(parser *omp.Parser, opts *options) (*output, error) {
+	if opts.step <= 0 {
+		return nil, fmt.Errorf("--step must be positive")
+	}
+
+	end, err := parseTime(opts.end, opts.time)
+	if err != nil {
+		d, durErr := time.ParseDuration(opts.end)
+		if durErr != nil {
+			return nil, err
+		}
+		end = opts.time.Add(d)
+	}
+
+	if end.Before(opts.time) {
+		return nil, fmt.Errorf("--end is before --time")
+	}
+
+	cnt := int(end.Sub(opts.time)/opts.step) + 1
+
+	return forecast(parser, opts, opts.time, opts.step, cnt)
+}
+
+func aqi(parser *omp.Parser, opts *options) (*output, error) {
+	if _, err := resolve(parser, opts); err != nil {
+		return nil, err
+	}
+
+	a, err := parser.GetOpenWeatherAQI(opts.lat, opts.lon, opts.time)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &omp.ResponseAQI{
+		Coord: omp.Coord{Lat: opts.lat, Lon: opts.lon},
+		List:  []omp.AQI{*a},
+	}
+
+	return aqiOutput(resp), nil
+}
+
+func unitSuffix(units omp.Units) (temp, speed string) {
+	switch units {
+	case omp.UnitsMetric:
+		return "°C", "m/s"
+	case omp.UnitsImperial:
+		return "°F", "mph"
+	default:
+		return "K", "m/s"
+	}
+}
+
+func description(weather []omp.Weather) string {
+	parts := make([]string, len(weather))
+	for i, w := range weather {
+		parts[i] = w.Description
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+)
+
+// output holds a result both as OpenWeather JSON and as flat rows for the
+// table and CSV formats.
+type output struct {
+	json   any
+	header []string
+	rows   [][]string
+}
+
+func (o *output) write(w io.Writer, format string) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(o.header, "\t"))
+		for _, row := range o.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(o.header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(o.rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(o.json)
+	}
+}
+
+func forecastOutput(v any, list []omp.Forecast, units omp.Units) *output {
+	tempUnit, speedUnit := unitSuffix(units)
+
+	out := &output{
+		json: v,
+		header: []string{
+			"time",
+			"temp " + tempUnit,
+			"feels_like " + tempUnit,
+			"humidity %",
+			"pressure hPa",
+			"wind " + speedUnit,
+			"wind_deg",
+			"rain mm",
+			"weather",
+		},
+	}
+
+	for _, f := range list {
+		out.rows = append(out.rows, []string{
+			f.DtTxt,
+			formatFloat(f.Main.Temp),
+			formatFloat(f.Main.FeelsLike),
+			strconv.Itoa(f.Main.Humidity),
+			strconv.Itoa(f.Main.Pressure),
+			formatFloat(f.Wind.Speed),
+			strconv.Itoa(f.Wind.Deg),
+			formatFloat(f.Rain.ThreeH),
+			description(f.Weather),
+		})
+	}
+
+	return out
+}
+
+func aqiOutput(resp *omp.ResponseAQI) *output {
+	out := &output{
+		json:   resp,
+		header: []string{"time", "aqi", "pm2_5", "pm10", "o3", "no2", "so2", "co"},
+	}
+
+	for _, a := range resp.List {
+		out.rows = append(out.rows, []string{
+			time.Unix(int64(a.Dt), 0).UTC().Format(omp.DtTxtLayout),
+			strconv.Itoa(a.Main.Aqi),
+			formatFloat(a.Components.Pm2_5),
+			formatFloat(a.Components.Pm10),
+			formatFloat(a.Components.O3),
+			formatFloat(a.Components.No2),
+			formatFloat(a.Components.So2),
+			formatFloat(a.Components.Co),
+		})
+	}
+
+	return out
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
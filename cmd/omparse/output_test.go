@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+)
+
+func TestOutput_Write(t *testing.T) {
+	list := []omp.Forecast{
+		{
+			DtTxt:   "2024-05-01 01:00:00",
+			Main:    omp.Main{Temp: 27.5, FeelsLike: 30.25, Humidity: 80, Pressure: 1011},
+			Wind:    omp.Wind{Speed: 3.1, Deg: 95},
+			Weather: []omp.Weather{{Description: "overcast clouds"}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string
+	}{
+		{
+			name:   "Test csv",
+			format: "csv",
+			want: []string{
+				"time,temp °C,feels_like °C,humidity %,pressure hPa,wind m/s,wind_deg,rain mm,weather",
+				"2024-05-01 01:00:00,27.50,30.25,80,1011,3.10,95,0.00,overcast clouds",
+			},
+		},
+		{
+			name:   "Test table",
+			format: "table",
+			want:   []string{"2024-05-01 01:00:00  27.50"},
+		},
+		{
+			name:   "Test json",
+			format: "json",
+			want:   []string{`"dt_txt": "2024-05-01 01:00:00"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := forecastOutput(list, list, omp.UnitsMetric).write(&buf, tt.format); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"now", now, false},
+		{"2024-05-02T09:00:00+08:00", time.Date(2024, 5, 2, 1, 0, 0, 0, time.UTC), false},
+		{"2024-05-02 03:30", time.Date(2024, 5, 2, 3, 30, 0, 0, time.UTC), false},
+		{"tomorrow", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTime(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Command owm-proxy serves a subset of the OpenWeather 2.5 API backed by
+// Open-Meteo, so existing OpenWeather clients only need a new base URL.
+//
+//	owm-proxy -addr :8080 -appid secret
+//	curl 'localhost:8080/data/2.5/weather?q=Denpasar,ID&units=metric&appid=secret'
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+	"github.com/saktibimantara/open-meteo-parser/instrumentation/promhooks"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	appid := flag.String("appid", os.Getenv("OWM_PROXY_APPID"), "API key clients must pass as appid; empty accepts any")
+	cloudfront := flag.String("cloudfront", "", "base URL for weather icons")
+	metricsAddr := flag.String("metrics-addr", "", "listen address for Prometheus metrics; empty disables them")
+	flag.Parse()
+
+	parser := omp.NewParser(*appid, *cloudfront).
+		SetRetryPolicy(omp.DefaultRetryPolicy()).
+		SetCircuitBreaker(omp.DefaultCircuitBreakerPolicy())
+
+	if *metricsAddr != "" {
+		metrics := promhooks.New()
+		parser.SetInstrumentation(metrics)
+
+		go func() {
+			log.Fatal(http.ListenAndServe(*metricsAddr, metrics))
+		}()
+	}
+
+	log.Printf("owm-proxy listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, newServer(parser, parser.APIKey)))
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+)
+
+const (
+	forecastStep   = 3 * time.Hour
+	forecastMaxCnt = 40
+
+	dailyDefaultCnt = 7
+)
+
+type backend interface {
+	ResolveCity(q string) (*omp.Place, error)
+	GetOpenWeatherForecast(latitude, longitude float64, startTime time.Time) (*omp.Forecast, error)
+	GetOpenWeatherForecastByCity(q string, startTime time.Time) (*omp.Forecast, error)
+	GetOpenWeatherForecastList(latitude, longitude float64, startTime time.Time, step time.Duration, cnt int) (*omp.Response3HoursStepForecast, error)
+	GetOpenWeatherDailyForecast(latitude, longitude float64, cnt int) (*omp.ResponseDailyForecast, error)
+	GetOpenWeatherAQI(latitude, longitude float64, startTime time.Time) (*omp.AQI, error)
+	GetOpenWeatherAQIForecast(latitude, longitude float64, startTime time.Time) (*omp.ResponseAQI, error)
+}
+
+type server struct {
+	backend backend
+	apiKey  string
+	now     func() time.Time
+	mux     *http.ServeMux
+}
+
+func newServer(b backend, apiKey string) *server {
+	s := &server{
+		backend: b,
+		apiKey:  apiKey,
+		now:     time.Now,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/data/2.5/weather", s.handleWeather)
+	s.mux.HandleFunc("/data/2.5/forecast", s.handleForecast)
+	s.mux.HandleFunc("/data/2.5/forecast/daily", s.handleDailyForecast)
+	s.mux.HandleFunc("/data/2.5/air_pollution", s.handleAirPollution)
+	s.mux.HandleFunc("/data/2.5/air_pollution/forecast", s.handleAirPollutionForecast)
+
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if s.apiKey != "" && r.URL.Query().Get("appid") != s.apiKey {
+		writeError(w, http.StatusUnauthorized, "Invalid API key. Please see https://openweathermap.org/faq#error401 for more info.")
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// request holds the OpenWeather query parameters shared by all endpoints.
+// lang is accepted for compatibility; descriptions are always English.
+type request struct {
+	lat, lon float64
+	q        string
+	units    omp.Units
+	lang     string
+}
+
+func parseRequest(r *http.Request) (*request, *omp.Error) {
+	query := r.URL.Query()
+
+	units, err := omp.ParseUnits(query.Get("units"))
+	if err != nil {
+		return nil, &omp.Error{Cod: "400", Message: err.Error()}
+	}
+
+	req := &request{
+		q:     query.Get("q"),
+		units: units,
+		lang:  query.Get("lang"),
+	}
+
+	if req.q != "" {
+		return req, nil
+	}
+
+	if query.Get("lat") == "" || query.Get("lon") == "" {
+		return nil, &omp.Error{Cod: "400", Message: "Nothing to geocode"}
+	}
+
+	req.lat, err = strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || req.lat < -90 || req.lat > 90 {
+		return nil, &omp.Error{Cod: "400", Message: "wrong latitude"}
+	}
+
+	req.lon, err = strconv.ParseFloat(query.Get("lon"), 64)
+	if err != nil || req.lon < -180 || req.lon > 180 {
+		return nil, &omp.Error{Cod: "400", Message: "wrong longitude"}
+	}
+
+	return req, nil
+}
+
+// coordinates resolves q when given, since the air pollution and list
+// endpoints only take coordinates.
+func (s *server) coordinates(req *request) (*omp.Place, error) {
+	if req.q == "" {
+		return nil, nil
+	}
+
+	place, err := s.backend.ResolveCity(req.q)
+	if err != nil {
+		return nil, err
+	}
+
+	req.lat, req.lon = place.Latitude, place.Longitude
+
+	return place, nil
+}
+
+func (s *server) handleWeather(w http.ResponseWriter, r *http.Request) {
+	req, reqErr := parseRequest(r)
+	if reqErr != nil {
+		writeOpenWeatherError(w, reqErr)
+		return
+	}
+
+	var forecast *omp.Forecast
+	var err error
+	if req.q != "" {
+		forecast, err = s.backend.GetOpenWeatherForecastByCity(req.q, s.now())
+	} else {
+		forecast, err = s.backend.GetOpenWeatherForecast(req.lat, req.lon, s.now())
+	}
+
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	converted := forecast.InUnits(req.units)
+	writeJSON(w, http.StatusOK, omp.NewCurrentWeather(&converted))
+}
+
+func (s *server) handleForecast(w http.ResponseWriter, r *http.Request) {
+	req, reqErr := parseRequest(r)
+	if reqErr != nil {
+		writeOpenWeatherError(w, reqErr)
+		return
+	}
+
+	cnt := forecastMaxCnt
+	if v := r.URL.Query().Get("cnt"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "cnt must be a positive integer")
+			return
+		}
+
+		if n < cnt {
+			cnt = n
+		}
+	}
+
+	place, err := s.coordinates(req)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	now := s.now()
+	start := now.Truncate(forecastStep)
+	if start.Before(now) {
+		start = start.Add(forecastStep)
+	}
+
+	resp, err := s.backend.GetOpenWeatherForecastList(req.lat, req.lon, start, forecastStep, cnt)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	if place != nil {
+		resp.City.ID = place.ID
+		resp.City.Name = place.Name
+		resp.City.Country = place.Country
+		resp.City.Population = place.Population
+	}
+
+	for i := range resp.List {
+		resp.List[i] = resp.List[i].InUnits(req.units)
+		resp.List[i].Main.Location = resp.City.Name
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *server) handleDailyForecast(w http.ResponseWriter, r *http.Request) {
+	req, reqErr := parseRequest(r)
+	if reqErr != nil {
+		writeOpenWeatherError(w, reqErr)
+		return
+	}
+
+	cnt := dailyDefaultCnt
+	if v := r.URL.Query().Get("cnt"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "cnt must be a positive integer")
+			return
+		}
+
+		cnt = min(n, omp.MaxDailyForecastDays)
+	}
+
+	place, err := s.coordinates(req)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	resp, err := s.backend.GetOpenWeatherDailyForecast(req.lat, req.lon, cnt)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	if place != nil {
+		resp.City.ID = place.ID
+		resp.City.Name = place.Name
+		resp.City.Country = place.Country
+		resp.City.Population = place.Population
+	}
+
+	for i := range resp.List {
+		resp.List[i] = resp.List[i].InUnits(req.units)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *server) handleAirPollution(w http.ResponseWriter, r *http.Request) {
+	req, reqErr := parseRequest(r)
+	if reqErr != nil {
+		writeOpenWeatherError(w, reqErr)
+		return
+	}
+
+	if _, err := s.coordinates(req); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	aqi, err := s.backend.GetOpenWeatherAQI(req.lat, req.lon, s.now())
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &omp.ResponseAQI{
+		Coord: omp.Coord{Lat: req.lat, Lon: req.lon},
+		List:  []omp.AQI{*aqi},
+	})
+}
+
+func (s *server) handleAirPollutionForecast(w http.ResponseWriter, r *http.Request) {
+	req, reqErr := parseRequest(r)
+	if reqErr != nil {
+		writeOpenWeatherError(w, reqErr)
+		return
+	}
+
+	if _, err := s.coordinates(req); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	resp, err := s.backend.GetOpenWeatherAQIForecast(req.lat, req.lon, s.now())
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeBackendError(w http.ResponseWriter, err error) {
+	writeOpenWeatherError(w, omp.ToOpenWeatherError(err))
+}
+
+func writeOpenWeatherError(w http.ResponseWriter, owErr *omp.Error) {
+	status, err := strconv.Atoi(owErr.Cod)
+	if err != nil {
+		status = http.StatusInternalServerError
+	}
+
+	writeJSON(w, status, owErr)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, &omp.Error{Cod: strconv.Itoa(status), Message: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+)
+
+type fakeBackend struct{}
+
+func (fakeBackend) ResolveCity(q string) (*omp.Place, error) {
+	if q != "Denpasar,ID" {
+		return nil, &omp.Error{Cod: "404", Message: "city not found"}
+	}
+
+	return &omp.Place{ID: 1645528, Name: "Denpasar", Country: "ID", Latitude: -8.65, Longitude: 115.21667}, nil
+}
+
+func (b fakeBackend) GetOpenWeatherForecast(latitude, longitude float64, startTime time.Time) (*omp.Forecast, error) {
+	return &omp.Forecast{
+		Dt:      int(startTime.Unix()),
+		Main:    omp.Main{Temp: 27},
+		Weather: []omp.Weather{{ID: 800, Main: "Clear"}},
+		Wind:    omp.Wind{Speed: 36},
+		City:    &omp.City{Coord: omp.Coord{Lat: latitude, Lon: longitude}},
+	}, nil
+}
+
+func (b fakeBackend) GetOpenWeatherForecastByCity(q string, startTime time.Time) (*omp.Forecast, error) {
+	place, err := b.ResolveCity(q)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, _ := b.GetOpenWeatherForecast(place.Latitude, place.Longitude, startTime)
+	forecast.City.Name = place.Name
+
+	return forecast, nil
+}
+
+func (b fakeBackend) GetOpenWeatherForecastList(latitude, longitude float64, startTime time.Time, step time.Duration, cnt int) (*omp.Response3HoursStepForecast, error) {
+	resp := &omp.Response3HoursStepForecast{Cod: "200", Cnt: cnt}
+	for i := 0; i < cnt; i++ {
+		forecast, _ := b.GetOpenWeatherForecast(latitude, longitude, startTime.Add(time.Duration(i)*step))
+		forecast.City = nil
+		resp.List = append(resp.List, *forecast)
+	}
+
+	return resp, nil
+}
+
+func (fakeBackend) GetOpenWeatherDailyForecast(latitude, longitude float64, cnt int) (*omp.ResponseDailyForecast, error) {
+	resp := &omp.ResponseDailyForecast{Cod: "200", Cnt: cnt}
+	for i := 0; i < cnt; i++ {
+		resp.List = append(resp.List, omp.DailyForecast{
+			Temp:    omp.DailyTemp{Day: 30, Min: 24, Max: 31},
+			Weather: []omp.Weather{{ID: 800, Main: "Clear"}},
+		})
+	}
+
+	return resp, nil
+}
+
+func (fakeBackend) GetOpenWeatherAQI(latitude, longitude float64, startTime time.Time) (*omp.AQI, error) {
+	return omp.NewAQIBuilder().SetPm2_5(10).SetDt(int(startTime.Unix())).Build(), nil
+}
+
+func (b fakeBackend) GetOpenWeatherAQIForecast(latitude, longitude float64, startTime time.Time) (*omp.ResponseAQI, error) {
+	aqi, _ := b.GetOpenWeatherAQI(latitude, longitude, startTime)
+	return &omp.ResponseAQI{List: []omp.AQI{*aqi, *aqi}}, nil
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		check      func(t *testing.T, body map[string]any)
+	}{
+		{
+			name:       "Test missing appid",
+			url:        "/data/2.5/weather?lat=-8.6&lon=115.2",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Test missing location",
+			url:        "/data/2.5/weather?appid=secret",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test wrong latitude",
+			url:        "/data/2.5/weather?lat=99&lon=115.2&appid=secret",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test weather by city in metric",
+			url:        "/data/2.5/weather?q=Denpasar,ID&units=metric&appid=secret",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				if body["name"] != "Denpasar" {
+					t.Errorf("name = %v", body["name"])
+				}
+				if temp := body["main"].(map[string]any)["temp"]; temp != 27.0 {
+					t.Errorf("temp = %v, want 27", temp)
+				}
+				if speed := body["wind"].(map[string]any)["speed"]; speed != 10.0 {
+					t.Errorf("wind speed = %v, want 10 m/s", speed)
+				}
+			},
+		},
+		{
+			name:       "Test unknown city",
+			url:        "/data/2.5/weather?q=Atlantis&appid=secret",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test forecast cnt",
+			url:        "/data/2.5/forecast?lat=-8.6&lon=115.2&cnt=3&appid=secret",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				if list := body["list"].([]any); len(list) != 3 {
+					t.Errorf("got %d entries, want 3", len(list))
+				}
+				temp := body["list"].([]any)[0].(map[string]any)["main"].(map[string]any)["temp"]
+				if temp != 300.15 {
+					t.Errorf("temp = %v, want 300.15 K", temp)
+				}
+				if _, ok := body["city"].(map[string]any)["coord"]; !ok {
+					t.Errorf("city has no coord: %v", body["city"])
+				}
+			},
+		},
+		{
+			name:       "Test daily forecast capped at 16 days",
+			url:        "/data/2.5/forecast/daily?q=Denpasar,ID&cnt=30&units=imperial&appid=secret",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				if list := body["list"].([]any); len(list) != 16 {
+					t.Errorf("got %d days, want 16", len(list))
+				}
+				if name := body["city"].(map[string]any)["name"]; name != "Denpasar" {
+					t.Errorf("city name = %v", name)
+				}
+				temp := body["list"].([]any)[0].(map[string]any)["temp"].(map[string]any)["day"]
+				if temp != 86.0 {
+					t.Errorf("temp.day = %v, want 86 °F", temp)
+				}
+			},
+		},
+		{
+			name:       "Test air pollution forecast by city",
+			url:        "/data/2.5/air_pollution/forecast?q=Denpasar,ID&appid=secret",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body map[string]any) {
+				if list := body["list"].([]any); len(list) != 2 {
+					t.Errorf("got %d entries, want 2", len(list))
+				}
+			},
+		},
+	}
+
+	s := newServer(fakeBackend{}, "secret")
+	s.now = func() time.Time { return time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC) }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.check != nil {
+				tt.check(t, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package open_meteo_parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+// MaxDailyForecastDays is the longest daily forecast Open-Meteo serves.
+const MaxDailyForecastDays = 16
+
+// local hours OpenWeather reports the morning, day, evening and night
+// temperatures for
+const (
+	mornHour  = 6
+	dayHour   = 12
+	eveHour   = 18
+	nightHour = 0
+)
+
+func generateDailyParams(lat, lon float64) *pom.ForecastParams {
+	params, err := pom.NewForecastParamsBuilder().
+		SetLatitude(lat).
+		SetLongitude(lon).
+		AddHourlyParam(
+			pom.Temperature2m,
+			pom.ApparentTemperature,
+			pom.RelativeHumidity2m,
+			pom.PressureMSL,
+			pom.CloudCover,
+		).
+		AddDailyParam(
+			pom.DailyTemperature2mMax,
+			pom.DailyTemperature2mMin,
+			pom.DailyWeatherCode,
+			pom.DailySunrise,
+			pom.DailySunset,
+			pom.DailyPrecipitationSum,
+			pom.DailyRainSum,
+			pom.DailyShowersSum,
+			pom.DailySnowfallSum,
+			pom.DailyPrecipitationProbabilityMax,
+			pom.DailyWindSpeed10mMax,
+			pom.DailyWindGusts10mMax,
+			pom.DailyWindDirection10mDominant,
+			pom.DailyUvIndexMax,
+			pom.DailyShortwaveRadiationSum,
+			pom.DailySunshineDuration,
+		).
+		Build()
+
+	if err != nil {
+		return nil
+	}
+
+	return &params
+}
+
+// GetOpenWeatherDailyForecast returns cnt days, up to MaxDailyForecastDays,
+// starting today in the location's timezone, shaped like OpenWeather's
+// daily forecast.
+func (p Parser) GetOpenWeatherDailyForecast(latitude, longitude float64, cnt int) (*ResponseDailyForecast, error) {
+	if cnt < 1 || cnt > MaxDailyForecastDays {
+		return nil, fmt.Errorf("invalid daily forecast: cnt %d, want 1 to %d", cnt, MaxDailyForecastDays)
+	}
+
+	if err := validateCoordinates(latitude, longitude); err != nil {
+		return nil, err
+	}
+
+	params := generateDailyParams(latitude, longitude)
+	if params == nil {
+		return nil, fmt.Errorf("%w: %f,%f", ErrInvalidCoordinates, latitude, longitude)
+	}
+
+	extra := p.forecastQuery()
+	extra.Set("forecast_days", strconv.Itoa(cnt))
+
+	openResp, err := p.fetchForecastParams(queryParams{params: params, extra: extra})
+	if err != nil {
+		return nil, err
+	}
+
+	if openResp.Daily == nil {
+		return nil, ErrNoForecastAtTime
+	}
+
+	resp := &ResponseDailyForecast{
+		Cod:  "200",
+		List: make([]DailyForecast, 0, cnt),
+	}
+
+	for i := 0; i < len(openResp.Daily.Time) && i < cnt; i++ {
+		resp.List = append(resp.List, dailyFromResponse(openResp, i))
+	}
+
+	resp.Cnt = len(resp.List)
+
+	loc := responseLocation(openResp.Timezone, openResp.UTCOffsetSeconds)
+	noon := time.Unix(int64(resp.List[0].Dt), 0)
+	resp.City = City{
+		Coord:    Coord{Lat: openResp.Latitude, Lon: openResp.Longitude},
+		Timezone: utcOffset(&noon, loc),
+		Sunrise:  resp.List[0].Sunrise,
+		Sunset:   resp.List[0].Sunset,
+	}
+
+	if p.geocoder != nil {
+		if place, err := p.geocoder.ReverseGeocode(latitude, longitude); err == nil && place != nil {
+			resp.City.setPlace(place)
+		}
+	}
+
+	return resp, nil
+}
+
+func dailyFromResponse(resp *pom.ForecastResponse, i int) DailyForecast {
+	loc := responseLocation(resp.Timezone, resp.UTCOffsetSeconds)
+	daily := resp.Daily
+
+	date := daily.Time[i].Time
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	at := func(hour int) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), hour, 0, 0, 0, loc)
+	}
+
+	d := DailyForecast{
+		Dt:            int(at(dayHour).Unix()),
+		MoonPhase:     math.Round(moonPhase(at(dayHour))*100) / 100,
+		Pop:           safeFloat64(valueAt(daily.PrecipitationProbabilityMax, i)) / 100,
+		Rain:          safeFloat64(valueAt(daily.RainSum, i)) + safeFloat64(valueAt(daily.ShowersSum, i)),
+		Snow:          safeFloat64(valueAt(daily.SnowfallSum, i)) * 10,
+		Precipitation: safeFloat64(valueAt(daily.PrecipitationSum, i)),
+		Speed:         safeFloat64(valueAt(daily.WindSpeed10mMax, i)),
+		Gust:          safeFloat64(valueAt(daily.WindGusts10mMax, i)),
+		Deg:           int(safeFloat64(valueAt(daily.WindDirection10mDominant, i))),
+		Uvi:           safeFloat64(valueAt(daily.UvIndexMax, i)),
+
+		SolarRadiation:   safeFloat64(valueAt(daily.ShortwaveRadiationSum, i)),
+		SunshineDuration: safeFloat64(valueAt(daily.SunshineDuration, i)),
+		Temp: DailyTemp{
+			Min: safeFloat64(valueAt(daily.Temperature2mMin, i)),
+			Max: safeFloat64(valueAt(daily.Temperature2mMax, i)),
+		},
+	}
+
+	// Open-Meteo's dominant direction is already a vector mean of the day
+	if valueAt(daily.WindSpeed10mMax, i) != nil {
+		d.Beaufort = Beaufort(d.Speed)
+		d.WindDescription = BeaufortDescription(d.Beaufort)
+	}
+
+	if valueAt(daily.WindDirection10mDominant, i) != nil {
+		d.WindDirection = CompassDirection(float64(d.Deg))
+	}
+
+	if valueAt(daily.UvIndexMax, i) != nil {
+		d.UVCategory = UVCategory(d.Uvi)
+		d.UVAdvice = UVAdvice(d.Uvi)
+	}
+
+	if i < len(daily.Sunrise) {
+		d.Sunrise = parseLocalUnix(&daily.Sunrise[i], loc)
+	}
+
+	if i < len(daily.Sunset) {
+		d.Sunset = parseLocalUnix(&daily.Sunset[i], loc)
+	}
+
+	rise, set := moonTimes(midnight, resp.Latitude, resp.Longitude)
+	if !rise.IsZero() {
+		d.Moonrise = int(rise.Unix())
+	}
+	if !set.IsZero() {
+		d.Moonset = int(set.Unix())
+	}
+
+	weather := ParseWeatherCode(pom.WeatherCodeClearSky)
+	if i < len(daily.WeatherCode) && daily.WeatherCode[i] != missingWeatherCode {
+		weather = ParseWeatherCode(daily.WeatherCode[i])
+	}
+	d.Weather = []Weather{*weather}
+
+	if hourly := resp.Hourly; hourly != nil {
+		index := make(map[int64]int, len(hourly.Time))
+		for j, t := range hourly.Time {
+			index[t.Time.Unix()] = j
+		}
+
+		sample := func(values []float64, hour int) float64 {
+			if j, ok := index[at(hour).Unix()]; ok {
+				return safeFloat64(valueAt(values, j))
+			}
+			return 0
+		}
+
+		d.Temp.Morn = sample(hourly.Temperature2m, mornHour)
+		d.Temp.Day = sample(hourly.Temperature2m, dayHour)
+		d.Temp.Eve = sample(hourly.Temperature2m, eveHour)
+		d.Temp.Night = sample(hourly.Temperature2m, nightHour)
+
+		d.FeelsLike.Morn = sample(hourly.ApparentTemperature, mornHour)
+		d.FeelsLike.Day = sample(hourly.ApparentTemperature, dayHour)
+		d.FeelsLike.Eve = sample(hourly.ApparentTemperature, eveHour)
+		d.FeelsLike.Night = sample(hourly.ApparentTemperature, nightHour)
+
+		var hours []int
+		for h := 0; h < 24; h++ {
+			if j, ok := index[at(h).Unix()]; ok {
+				hours = append(hours, j)
+			}
+		}
+
+		d.Humidity = int(math.Round(meanAt(hourly.RelativeHumidity2m, hours)))
+		d.Pressure = int(math.Round(meanAt(hourly.PressureMSL, hours)))
+		d.Clouds = int(math.Round(meanAt(hourly.CloudCover, hours)))
+	}
+
+	return d
+}
+
+// valueAt returns values[i], or nil when it is out of range or was null.
+func valueAt(values []float64, i int) *float64 {
+	if i < 0 || i >= len(values) {
+		return nil
+	}
+
+	return known(&values[i])
+}
+
+func meanAt(values []float64, indexes []int) float64 {
+	var sum float64
+	var n int
+	for _, i := range indexes {
+		if v := valueAt(values, i); v != nil {
+			sum += *v
+			n++
+		}
+	}
+
+	if n == 0 {
+		return 0
+	}
+
+	return sum / float64(n)
+}
@@ -0,0 +1,180 @@
+package open_meteo_parser
+
+import (
+	"math"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const fakeDailyJSON = `{
+	"latitude": -8.65,
+	"longitude": 115.22,
+	"timezone": "Asia/Makassar",
+	"hourly": {
+		"time": ["2024-05-01T00:00", "2024-05-01T06:00", "2024-05-01T12:00", "2024-05-01T18:00", "2024-05-02T00:00"],
+		"temperature_2m": [25.0, 24.5, 30.5, 27.0, 25.5],
+		"apparent_temperature": [27.0, 26.0, 34.0, 30.0, 27.5],
+		"relative_humidity_2m": [90, 92, 60, 78, 88],
+		"pressure_msl": [1010, 1011, 1009, 1010, 1011],
+		"cloud_cover": [20, 40, 60, 80, 10]
+	},
+	"daily": {
+		"time": ["2024-05-01", "2024-05-02"],
+		"temperature_2m_max": [31.2, 30.8],
+		"temperature_2m_min": [24.3, 24.9],
+		"weather_code": [61, 3],
+		"sunrise": ["2024-05-01T06:18", "2024-05-02T06:18"],
+		"sunset": ["2024-05-01T18:11", "2024-05-02T18:10"],
+		"precipitation_sum": [5.5, 0],
+		"rain_sum": [4.5, 0],
+		"showers_sum": [1.0, 0],
+		"snowfall_sum": [0, 0],
+		"precipitation_probability_max": [80, 10],
+		"wind_speed_10m_max": [18.0, 12.0],
+		"wind_gusts_10m_max": [35.0, 22.0],
+		"wind_direction_10m_dominant": [120, 135],
+		"uv_index_max": [9.5, 10.1],
+		"shortwave_radiation_sum": [21.3, 24.8],
+		"sunshine_duration": [28800, 36000]
+	}
+}`
+
+func TestParser_GetOpenWeatherDailyForecast(t *testing.T) {
+	p, caller := newFakeParser(fakeDailyJSON)
+
+	if _, err := p.GetOpenWeatherDailyForecast(-8.65, 115.22, MaxDailyForecastDays+1); err == nil {
+		t.Error("expected error for cnt above the maximum")
+	}
+
+	resp, err := p.GetOpenWeatherDailyForecast(-8.65, 115.22, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(caller.calls()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := u.Query().Get("forecast_days"); got != "16" {
+		t.Errorf("forecast_days = %q, want 16", got)
+	}
+
+	if resp.Cnt != 2 || len(resp.List) != 2 {
+		t.Fatalf("got %d days, want 2", resp.Cnt)
+	}
+
+	loc, err := time.LoadLocation("Asia/Makassar")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	day := resp.List[0]
+
+	if want := time.Date(2024, 5, 1, 12, 0, 0, 0, loc).Unix(); int64(day.Dt) != want {
+		t.Errorf("dt = %d, want %d", day.Dt, want)
+	}
+
+	if want := time.Date(2024, 5, 1, 6, 18, 0, 0, loc).Unix(); int64(day.Sunrise) != want {
+		t.Errorf("sunrise = %d, want %d", day.Sunrise, want)
+	}
+
+	wantTemp := DailyTemp{Day: 30.5, Min: 24.3, Max: 31.2, Night: 25.0, Eve: 27.0, Morn: 24.5}
+	if day.Temp != wantTemp {
+		t.Errorf("temp = %+v, want %+v", day.Temp, wantTemp)
+	}
+
+	wantFeelsLike := DailyFeelsLike{Day: 34.0, Night: 27.0, Eve: 30.0, Morn: 26.0}
+	if day.FeelsLike != wantFeelsLike {
+		t.Errorf("feels_like = %+v, want %+v", day.FeelsLike, wantFeelsLike)
+	}
+
+	if day.Humidity != 80 || day.Pressure != 1010 || day.Clouds != 50 {
+		t.Errorf("humidity %d, pressure %d, clouds %d", day.Humidity, day.Pressure, day.Clouds)
+	}
+
+	if day.Pop != 0.8 || day.Rain != 5.5 || day.Precipitation != 5.5 || day.Uvi != 9.5 || day.Gust != 35 || day.Deg != 120 {
+		t.Errorf("unexpected day %+v", day)
+	}
+
+	if day.Beaufort != 3 || day.WindDescription != "Gentle breeze" || day.WindDirection != "ESE" {
+		t.Errorf("beaufort %d %q, direction %q", day.Beaufort, day.WindDescription, day.WindDirection)
+	}
+
+	if day.UVCategory != UVVeryHigh || day.SolarRadiation != 21.3 || day.SunshineDuration != 28800 {
+		t.Errorf("uv category %q, solar radiation %v, sunshine %v", day.UVCategory, day.SolarRadiation, day.SunshineDuration)
+	}
+
+	if day.Weather[0].ID != 500 {
+		t.Errorf("weather id = %d, want 500", day.Weather[0].ID)
+	}
+
+	if day.Moonrise == 0 && day.Moonset == 0 {
+		t.Error("no moonrise or moonset")
+	}
+
+	if resp.City.Timezone != 8*3600 || resp.City.Sunrise != day.Sunrise {
+		t.Errorf("unexpected city %+v", resp.City)
+	}
+
+	// the daily forecast is read from the selected model
+	if _, err := p.SetModel(ModelICON).GetOpenWeatherDailyForecast(-8.65, 115.22, 16); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := caller.calls()
+	u, err = url.Parse(calls[len(calls)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := u.Query().Get("models"); got != string(ModelICON) {
+		t.Errorf("models = %q, want %q", got, ModelICON)
+	}
+}
+
+func TestMoonPhase(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want float64
+	}{
+		{name: "Test full moon", t: time.Date(2024, 4, 23, 23, 49, 0, 0, time.UTC), want: 0.5},
+		{name: "Test first quarter", t: time.Date(2024, 5, 15, 11, 48, 0, 0, time.UTC), want: 0.25},
+		{name: "Test last quarter", t: time.Date(2024, 5, 1, 11, 27, 0, 0, time.UTC), want: 0.75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := moonPhase(tt.t); math.Abs(got-tt.want) > 0.02 {
+				t.Errorf("moonPhase() = %.3f, want %.2f", got, tt.want)
+			}
+		})
+	}
+
+	newMoon := moonPhase(time.Date(2024, 5, 8, 3, 22, 0, 0, time.UTC))
+	if newMoon > 0.02 && newMoon < 0.98 {
+		t.Errorf("new moon phase = %.3f", newMoon)
+	}
+}
+
+func TestMoonTimes(t *testing.T) {
+	start := time.Date(2024, 5, 8, 0, 0, 0, 0, time.FixedZone("WITA", 8*3600))
+
+	rise, set := moonTimes(start, -8.65, 115.22)
+	if rise.IsZero() || set.IsZero() {
+		t.Fatalf("rise %v, set %v", rise, set)
+	}
+
+	for _, tm := range []time.Time{rise, set} {
+		if alt := moonAltitude(tm, -8.65, 115.22) - moonHorizon; math.Abs(alt) > toRadians(0.5) {
+			t.Errorf("moon altitude at %v is %.2f°", tm, alt*180/math.Pi)
+		}
+	}
+
+	// the new moon rises and sets with the sun
+	if rise.Hour() < 5 || rise.Hour() > 7 || set.Hour() < 17 || set.Hour() > 19 {
+		t.Errorf("rise %v after set %v", rise, set)
+	}
+}
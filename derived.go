@@ -0,0 +1,167 @@
+package open_meteo_parser
+
+import (
+	"math"
+	"strings"
+)
+
+// Derived quantities are computed from the temperature, humidity and wind
+// already fetched, in °C, % and km/h.
+
+// windChillMax and windChillMinSpeed bound where the wind chill index is
+// defined, in °C and km/h.
+const (
+	windChillMax      = 10
+	windChillMinSpeed = 4.8
+)
+
+// heatIndexMin is the temperature in °C (80 °F) from which the heat index is
+// used as the feels-like temperature.
+const heatIndexMin = 26.7
+
+// DewPoint returns the dew point in °C using the Magnus formula with the
+// Alduchov and Eskridge coefficients.
+func DewPoint(celsius, humidity float64) float64 {
+	const a, b = 17.625, 243.04
+
+	gamma := math.Log(humidity/100) + a*celsius/(b+celsius)
+	return b * gamma / (a - gamma)
+}
+
+// HeatIndex returns the NWS heat index in °C, using Rothfusz' regression
+// with the NWS adjustments.
+func HeatIndex(celsius, humidity float64) float64 {
+	t := celsius*9/5 + 32
+
+	hi := 0.5 * (t + 61 + (t-68)*1.2 + humidity*0.094)
+	if (hi+t)/2 >= 80 {
+		hi = -42.379 + 2.04901523*t + 10.14333127*humidity -
+			0.22475541*t*humidity - 0.00683783*t*t -
+			0.05481717*humidity*humidity + 0.00122874*t*t*humidity +
+			0.00085282*t*humidity*humidity - 0.00000199*t*t*humidity*humidity
+
+		switch {
+		case humidity < 13 && t >= 80 && t <= 112:
+			hi -= (13 - humidity) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+		case humidity > 85 && t >= 80 && t <= 87:
+			hi += (humidity - 85) / 10 * (87 - t) / 5
+		}
+	}
+
+	return (hi - 32) * 5 / 9
+}
+
+// WindChill returns the North American wind chill index in °C. Outside its
+// range, above 10 °C or below 4.8 km/h, it is the air temperature.
+func WindChill(celsius, kmh float64) float64 {
+	if celsius > windChillMax || kmh < windChillMinSpeed {
+		return celsius
+	}
+
+	v := math.Pow(kmh, 0.16)
+	return 13.12 + 0.6215*celsius - 11.37*v + 0.3965*celsius*v
+}
+
+// Humidex returns the Environment Canada humidex in °C.
+func Humidex(celsius, humidity float64) float64 {
+	dew := DewPoint(celsius, humidity)
+	e := 6.11 * math.Exp(5417.7530*(1/273.16-1/(273.15+dew)))
+
+	return celsius + 0.5555*(e-10)
+}
+
+// WetBulb returns the wet-bulb temperature in °C using Stull's empirical
+// formula, valid at sea-level pressure for 5 to 99 % humidity.
+func WetBulb(celsius, humidity float64) float64 {
+	return celsius*math.Atan(0.151977*math.Sqrt(humidity+8.313659)) +
+		math.Atan(celsius+humidity) - math.Atan(humidity-1.676331) +
+		0.00391838*math.Pow(humidity, 1.5)*math.Atan(0.023101*humidity) -
+		4.686035
+}
+
+// derive fills the forecast's derived quantities from its temperature,
+// humidity and wind, recording each with the coarser resolution of its
+// inputs, and approximates the feels-like temperature when Open-Meteo has
+// none. Open-Meteo's apparent temperature is only requested at minutely15.
+func (f *Forecast) derive(sources fieldSources) {
+	f.Derived = nil
+	delete(f.approximated, "main.feels_like")
+	for field := range sources {
+		if strings.HasPrefix(field, "derived.") {
+			delete(sources, field)
+		}
+	}
+
+	temp, ok := sources["main.temp"]
+	if !ok {
+		return
+	}
+
+	wind, hasWind := sources["wind.speed"]
+	humidity, hasHumidity := sources["main.humidity"]
+	rh := float64(f.Main.Humidity)
+
+	// the wind chill when it is cold and windy, the heat index when it is
+	// hot, and the air temperature otherwise
+	if _, ok := sources["main.feels_like"]; !ok {
+		switch {
+		case hasWind && f.Main.Temp <= windChillMax && f.Wind.Speed >= windChillMinSpeed:
+			f.Main.FeelsLike = WindChill(f.Main.Temp, f.Wind.Speed)
+			f.approximate("main.feels_like", coarser(temp, wind))
+		case hasHumidity && f.Main.Temp >= heatIndexMin:
+			f.Main.FeelsLike = HeatIndex(f.Main.Temp, rh)
+			f.approximate("main.feels_like", coarser(temp, humidity))
+		default:
+			f.Main.FeelsLike = f.Main.Temp
+			f.approximate("main.feels_like", temp)
+		}
+	}
+
+	if hasWind || hasHumidity && f.Main.Humidity > 0 {
+		f.Derived = &Derived{}
+	}
+
+	if hasWind {
+		f.Derived.WindChill = WindChill(f.Main.Temp, f.Wind.Speed)
+		sources["derived.wind_chill"] = coarser(temp, wind)
+	}
+
+	if !hasHumidity || f.Main.Humidity <= 0 {
+		return
+	}
+
+	res := coarser(temp, humidity)
+
+	f.Derived.DewPoint = DewPoint(f.Main.Temp, rh)
+	f.Derived.HeatIndex = HeatIndex(f.Main.Temp, rh)
+	f.Derived.Humidex = Humidex(f.Main.Temp, rh)
+	f.Derived.WetBulb = WetBulb(f.Main.Temp, rh)
+
+	for _, field := range []string{"derived.dew_point", "derived.heat_index", "derived.humidex", "derived.wet_bulb"} {
+		sources[field] = res
+	}
+}
+
+// approximate records field as computed by the parser, in place of a missing
+// upstream value, from inputs of resolution res.
+func (f *Forecast) approximate(field string, res Resolution) {
+	if f.approximated == nil {
+		f.approximated = fieldSources{}
+	}
+
+	f.approximated[field] = res
+}
+
+var resolutionOrder = map[Resolution]int{
+	ResolutionMinutely15: 0,
+	ResolutionHourly:     1,
+	ResolutionDaily:      2,
+}
+
+func coarser(a, b Resolution) Resolution {
+	if resolutionOrder[b] > resolutionOrder[a] {
+		return b
+	}
+
+	return a
+}
@@ -0,0 +1,158 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+func TestDerivedQuantities(t *testing.T) {
+	tests := []struct {
+		name      string
+		fn        func(a, b float64) float64
+		a, b      float64
+		want      float64
+		tolerance float64
+	}{
+		// dew point tables, °C and %
+		{"DewPoint", DewPoint, 20, 50, 9.3, 0.2},
+		{"DewPoint", DewPoint, 30, 70, 23.9, 0.2},
+		{"DewPoint", DewPoint, 0, 100, 0, 0.1},
+		{"DewPoint", DewPoint, -10, 80, -12.8, 0.2},
+
+		// NWS heat index chart, converted from °F
+		{"HeatIndex", HeatIndex, 26.7, 40, 26.7, 0.6},
+		{"HeatIndex", HeatIndex, 32.2, 60, 37.8, 0.6},
+		{"HeatIndex", HeatIndex, 35.6, 50, 42.2, 0.6},
+		{"HeatIndex", HeatIndex, 37.8, 40, 42.8, 0.6},
+		{"HeatIndex", HeatIndex, 30, 90, 40.6, 0.6},
+
+		// Environment Canada wind chill table, °C and km/h
+		{"WindChill", WindChill, -10, 20, -18, 0.5},
+		{"WindChill", WindChill, -20, 30, -33, 0.5},
+		{"WindChill", WindChill, 0, 10, -3, 0.5},
+		{"WindChill", WindChill, -30, 50, -49, 0.5},
+		{"WindChill", WindChill, 15, 30, 15, 0},
+		{"WindChill", WindChill, -5, 3, -5, 0},
+
+		// Environment Canada humidex table, °C and %
+		{"Humidex", Humidex, 30, 55, 38, 1},
+		{"Humidex", Humidex, 35, 60, 48, 1},
+		{"Humidex", Humidex, 25, 40, 26, 1},
+
+		// Stull (2011) and psychrometric charts, °C and %
+		{"WetBulb", WetBulb, 20, 50, 13.7, 0.1},
+		{"WetBulb", WetBulb, 30, 80, 27.2, 0.3},
+		{"WetBulb", WetBulb, 35, 20, 19.4, 0.3},
+	}
+
+	for _, tt := range tests {
+		if got := tt.fn(tt.a, tt.b); math.Abs(got-tt.want) > tt.tolerance {
+			t.Errorf("%s(%v, %v) = %.2f, want %v ± %v", tt.name, tt.a, tt.b, got, tt.want, tt.tolerance)
+		}
+	}
+}
+
+func TestParseToForecast_Derived(t *testing.T) {
+	f64 := func(v float64) *float64 { return &v }
+
+	t.Run("Test cold and windy", func(t *testing.T) {
+		forecast, sources := parseForecast(pom.NearestForecast{
+			HourlyForecast: &pom.NearestHourlyForecast{
+				Temperature2m: f64(-10),
+				WindSpeed10m:  f64(20),
+			},
+		})
+
+		if math.Abs(forecast.Main.FeelsLike-WindChill(-10, 20)) > 1e-9 {
+			t.Errorf("FeelsLike = %v, want the wind chill", forecast.Main.FeelsLike)
+		}
+
+		if sources["derived.wind_chill"] != ResolutionHourly {
+			t.Errorf("unexpected sources %v", sources)
+		}
+
+		// the approximation is not passed off as Open-Meteo's value
+		if _, ok := sources["main.feels_like"]; ok || forecast.approximated["main.feels_like"] != ResolutionHourly {
+			t.Errorf("feels_like sources %v, approximated %v", sources, forecast.approximated)
+		}
+
+		if _, ok := sources["derived.dew_point"]; ok {
+			t.Error("dew point derived without humidity")
+		}
+
+		// only derived values are converted, so the dew point is not 0 °C
+		d := forecast.InUnits(UnitsStandard).Derived
+		if d.DewPoint != 0 || math.Abs(d.WindChill-(WindChill(-10, 20)+273.15)) > 1e-9 {
+			t.Errorf("standard derived %+v", d)
+		}
+	})
+
+	t.Run("Test nothing to derive", func(t *testing.T) {
+		forecast, _ := parseForecast(pom.NearestForecast{
+			HourlyForecast: &pom.NearestHourlyForecast{
+				Temperature2m: f64(20),
+			},
+		})
+
+		if forecast.Derived != nil || forecast.InUnits(UnitsImperial).Derived != nil {
+			t.Errorf("Derived = %+v, want nil", forecast.Derived)
+		}
+
+		forecast.nullable = true
+		data, err := json.Marshal(forecast)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(string(data), `"derived"`) {
+			t.Errorf("derived emitted: %s", data)
+		}
+	})
+
+	t.Run("Test hot and humid", func(t *testing.T) {
+		forecast, sources := parseForecast(pom.NearestForecast{
+			Minutely15Forecast: &pom.NearestMinute15Forecast{
+				Temperature2m:      f64(32.2),
+				RelativeHumidity2m: f64(60),
+			},
+			HourlyForecast: &pom.NearestHourlyForecast{
+				WindSpeed10m: f64(5),
+			},
+		})
+
+		if math.Abs(forecast.Main.FeelsLike-HeatIndex(32.2, 60)) > 1e-9 {
+			t.Errorf("FeelsLike = %v, want the heat index", forecast.Main.FeelsLike)
+		}
+
+		d := forecast.Derived
+		if d.DewPoint != DewPoint(32.2, 60) || d.Humidex != Humidex(32.2, 60) || d.WetBulb != WetBulb(32.2, 60) || d.WindChill != 32.2 {
+			t.Errorf("unexpected derived %+v", d)
+		}
+
+		if sources["derived.dew_point"] != ResolutionMinutely15 || sources["derived.wind_chill"] != ResolutionHourly {
+			t.Errorf("unexpected sources %v", sources)
+		}
+
+		if got := forecast.InUnits(UnitsImperial).Derived.DewPoint; math.Abs(got-(d.DewPoint*9/5+32)) > 1e-9 {
+			t.Errorf("imperial dew point = %v", got)
+		}
+	})
+
+	t.Run("Test apparent temperature kept", func(t *testing.T) {
+		forecast, _ := parseForecast(pom.NearestForecast{
+			Minutely15Forecast: &pom.NearestMinute15Forecast{
+				Temperature2m:       f64(-10),
+				ApparentTemperature: f64(-14),
+				WindSpeed10m:        f64(20),
+			},
+		})
+
+		if forecast.Main.FeelsLike != -14 {
+			t.Errorf("FeelsLike = %v, want -14", forecast.Main.FeelsLike)
+		}
+	})
+}
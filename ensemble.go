@@ -0,0 +1,343 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ensemblePath = "/v1/ensemble"
+
+	// defaultEnsembleModel is ECMWF's ensemble of a control run and 50
+	// members, which runs 15 days ahead.
+	defaultEnsembleModel = "ecmwf_ifs025"
+	ensembleMembers      = 51
+	ensembleForecastDays = 15
+
+	// precipitationThreshold is the precipitation in mm from which a member
+	// counts as wet.
+	precipitationThreshold = 0.1
+)
+
+var ensembleHourlyParams = []string{
+	"temperature_2m",
+	"precipitation",
+	"wind_speed_10m",
+}
+
+type (
+	// EnsembleForecast summarizes the ensemble members at one time.
+	// Precipitation is the hour before Dt, and Pop is the share of members
+	// with at least 0.1 mm in that hour.
+	EnsembleForecast struct {
+		Dt            int           `json:"dt"`
+		DtTxt         string        `json:"dt_txt"`
+		Members       int           `json:"members"`
+		Temp          EnsembleStats `json:"temp"`
+		Precipitation EnsembleStats `json:"precipitation"`
+		WindSpeed     EnsembleStats `json:"wind_speed"`
+		Pop           float64       `json:"pop"`
+	}
+
+	// EnsembleStats is the spread of one value across the ensemble members.
+	EnsembleStats struct {
+		Mean   float64 `json:"mean"`
+		Median float64 `json:"median"`
+		P10    float64 `json:"p10"`
+		P25    float64 `json:"p25"`
+		P75    float64 `json:"p75"`
+		P90    float64 `json:"p90"`
+	}
+
+	// ResponseEnsembleForecast is an hourly ensemble series. Timezone is the
+	// location's offset from UTC in seconds.
+	ResponseEnsembleForecast struct {
+		Coord    Coord              `json:"coord"`
+		Timezone int                `json:"timezone"`
+		Model    string             `json:"model"`
+		Cnt      int                `json:"cnt"`
+		List     []EnsembleForecast `json:"list"`
+	}
+)
+
+// ensembleResponse is the ensemble API's response, requested with unix
+// times. Each variable comes once per member, as "temperature_2m" for the
+// control run and "temperature_2m_member01" onwards for the others.
+type ensembleResponse struct {
+	Latitude         float64                    `json:"latitude"`
+	Longitude        float64                    `json:"longitude"`
+	Timezone         string                     `json:"timezone"`
+	UTCOffsetSeconds int                        `json:"utc_offset_seconds"`
+	Hourly           map[string]json.RawMessage `json:"hourly"`
+
+	times   []int64
+	members map[string][][]*float64
+}
+
+// SetEnsemblePop controls whether forecasts carry a probability of
+// precipitation computed from the ensemble members. Enabling it costs an
+// extra request per forecast, shared by a forecast list.
+func (p *Parser) SetEnsemblePop(enabled bool) *Parser {
+	p.ensemblePop = enabled
+	return p
+}
+
+// GetEnsembleForecast returns hours hourly summaries of Open-Meteo's
+// ensemble forecast from startTime's hour, to show how uncertain the
+// forecast is. The list stops early at the end of the ensemble forecast.
+func (p Parser) GetEnsembleForecast(latitude, longitude float64, startTime time.Time, hours int) (*ResponseEnsembleForecast, error) {
+	if hours < 1 {
+		return nil, fmt.Errorf("invalid ensemble forecast: hours %d", hours)
+	}
+
+	resp, err := p.fetchEnsemble(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	i, ok := nearestHour(resp.times, startTime.Truncate(time.Hour))
+	if !ok {
+		return nil, ErrNoForecastAtTime
+	}
+
+	series := &ResponseEnsembleForecast{
+		Coord: Coord{Lat: resp.Latitude, Lon: resp.Longitude},
+		Model: defaultEnsembleModel,
+		List:  make([]EnsembleForecast, 0, hours),
+	}
+
+	for ; i < len(resp.times) && len(series.List) < hours; i++ {
+		series.List = append(series.List, resp.forecastAt(i))
+	}
+
+	series.Cnt = len(series.List)
+
+	loc := responseLocation(resp.Timezone, resp.UTCOffsetSeconds)
+	first := time.Unix(int64(series.List[0].Dt), 0)
+	series.Timezone = utcOffset(&first, loc)
+
+	return series, nil
+}
+
+func (p Parser) fetchEnsemble(lat, lon float64) (*ensembleResponse, error) {
+	if err := validateCoordinates(lat, lon); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(lat, 'f', -1, 64))
+	query.Set("longitude", strconv.FormatFloat(lon, 'f', -1, 64))
+	query.Set("hourly", strings.Join(ensembleHourlyParams, ","))
+	query.Set("models", defaultEnsembleModel)
+	query.Set("forecast_days", strconv.Itoa(ensembleForecastDays))
+	query.Set("timeformat", "unixtime")
+	if p.timezone != "" {
+		query.Set("timezone", p.timezone)
+	}
+
+	key := p.apiURL("ensemble", ensemblePath) + "?" + query.Encode()
+	resp, err := p.flights.do(key, func() (any, error) {
+		var data []byte
+		done := p.start(OperationFetchEnsemble)
+		err := p.call(membersRequestWeight(query, ensembleMembers), func() (err error) {
+			data, err = fetch(p.callApi, key)
+			return err
+		})
+		done(err)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp ensembleResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, err
+		}
+
+		if err := resp.index(); err != nil {
+			return nil, err
+		}
+
+		return &resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*ensembleResponse), nil
+}
+
+// index decodes the hourly series and groups them by variable.
+func (r *ensembleResponse) index() error {
+	r.members = map[string][][]*float64{}
+
+	keys := make([]string, 0, len(r.Hourly))
+	for key := range r.Hourly {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == "time" {
+			if err := json.Unmarshal(r.Hourly[key], &r.times); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var values []*float64
+		if err := json.Unmarshal(r.Hourly[key], &values); err != nil {
+			return err
+		}
+
+		variable := key
+		if i := strings.Index(key, "_member"); i >= 0 {
+			variable = key[:i]
+		}
+
+		r.members[variable] = append(r.members[variable], values)
+	}
+
+	return nil
+}
+
+// valuesAt returns the members' values of variable at index i, skipping
+// members without one.
+func (r *ensembleResponse) valuesAt(variable string, i int) []float64 {
+	var values []float64
+	for _, member := range r.members[variable] {
+		if i < len(member) && member[i] != nil {
+			values = append(values, *member[i])
+		}
+	}
+
+	return values
+}
+
+func (r *ensembleResponse) forecastAt(i int) EnsembleForecast {
+	dt := time.Unix(r.times[i], 0)
+	temp := r.valuesAt("temperature_2m", i)
+	precipitation := r.valuesAt("precipitation", i)
+
+	return EnsembleForecast{
+		Dt:            int(dt.Unix()),
+		DtTxt:         dt.UTC().Format(DtTxtLayout),
+		Members:       len(temp),
+		Temp:          ensembleStats(temp),
+		Precipitation: ensembleStats(precipitation),
+		WindSpeed:     ensembleStats(r.valuesAt("wind_speed_10m", i)),
+		Pop:           wetShare(precipitation),
+	}
+}
+
+// pop returns the share of members with at least 0.1 mm of precipitation in
+// the step starting at t, and the time of the step's first sample. Hourly
+// precipitation is the hour before its time, so the step covers the samples
+// after t up to t+step.
+func (r *ensembleResponse) pop(t time.Time, step time.Duration) (float64, int64, bool) {
+	members := r.members["precipitation"]
+	totals := make([]float64, len(members))
+	first := int64(0)
+
+	for i, ts := range r.times {
+		if ts <= t.Unix() || ts > t.Add(step).Unix() {
+			continue
+		}
+
+		if first == 0 {
+			first = ts
+		}
+
+		for m, member := range members {
+			if i < len(member) && member[i] != nil {
+				totals[m] += *member[i]
+			}
+		}
+	}
+
+	if first == 0 || len(members) == 0 {
+		return 0, 0, false
+	}
+
+	return wetShare(totals), first, true
+}
+
+// fillPop sets the forecast's probability of precipitation over the step
+// starting at t from the ensemble. Times the ensemble does not cover leave
+// it unset.
+func (r *ensembleResponse) fillPop(forecast *Forecast, t time.Time, step time.Duration) {
+	pop, dt, ok := r.pop(t, step)
+	if !ok {
+		return
+	}
+
+	forecast.Pop = pop
+	forecast.sources["pop"] = ResolutionHourly
+
+	if forecast.Provenance != nil {
+		forecast.Provenance["pop"] = FieldProvenance{
+			Resolution: ResolutionHourly,
+			Dt:         int(dt),
+			Offset:     int(dt - t.Unix()),
+		}
+	}
+}
+
+func wetShare(precipitation []float64) float64 {
+	if len(precipitation) == 0 {
+		return 0
+	}
+
+	wet := 0
+	for _, mm := range precipitation {
+		if mm >= precipitationThreshold {
+			wet++
+		}
+	}
+
+	return roundTo(float64(wet)/float64(len(precipitation)), 2)
+}
+
+func ensembleStats(values []float64) EnsembleStats {
+	if len(values) == 0 {
+		return EnsembleStats{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return EnsembleStats{
+		Mean:   roundTo(sum/float64(len(sorted)), 2),
+		Median: roundTo(percentile(sorted, 0.5), 2),
+		P10:    roundTo(percentile(sorted, 0.1), 2),
+		P25:    roundTo(percentile(sorted, 0.25), 2),
+		P75:    roundTo(percentile(sorted, 0.75), 2),
+		P90:    roundTo(percentile(sorted, 0.9), 2),
+	}
+}
+
+// percentile interpolates linearly between the closest ranks of sorted.
+func percentile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo+1 >= len(sorted) {
+		return sorted[lo]
+	}
+
+	return sorted[lo] + (pos-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+func roundTo(v float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,162 @@
+package open_meteo_parser
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeEnsembleJSON = `{
+	"latitude": -8.75,
+	"longitude": 115.25,
+	"timezone": "Asia/Makassar",
+	"utc_offset_seconds": 28800,
+	"hourly": {
+		"time": [1714521600, 1714525200, 1714528800, 1714532400],
+		"temperature_2m": [27.0, 27.4, 28.0, 28.2],
+		"temperature_2m_member01": [26.0, 26.8, 27.5, 27.9],
+		"temperature_2m_member02": [28.0, 28.1, 29.0, 29.4],
+		"temperature_2m_member03": [27.5, 27.6, 28.4, 28.8],
+		"temperature_2m_member04": [26.5, null, 27.0, 27.1],
+		"precipitation": [0, 0, 0.4, 1.2],
+		"precipitation_member01": [0, 0.1, 0, 0],
+		"precipitation_member02": [0, 0, 0.05, 0.05],
+		"precipitation_member03": [0, 0, 0.3, 2.0],
+		"precipitation_member04": [0, 0, 0, 0],
+		"wind_speed_10m": [10, 12, 14, 16]
+	}
+}`
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{26.0, 26.5, 27.0, 27.5, 28.0}
+
+	tests := map[float64]float64{0: 26, 0.1: 26.2, 0.25: 26.5, 0.5: 27, 0.75: 27.5, 0.9: 27.8, 1: 28}
+	for q, want := range tests {
+		if got := roundTo(percentile(sorted, q), 2); got != want {
+			t.Errorf("percentile(%v) = %v, want %v", q, got, want)
+		}
+	}
+
+	if got := percentile([]float64{3}, 0.9); got != 3 {
+		t.Errorf("percentile of one value = %v, want 3", got)
+	}
+}
+
+func TestParser_GetEnsembleForecast(t *testing.T) {
+	p, caller := newFakeParser(fakeEnsembleJSON)
+	p.SetQuota(FreeTierQuota())
+	p.limiter.now = fakeNow
+	p.limiter.last = fakeNow()
+
+	series, err := p.GetEnsembleForecast(-8.72, 115.17, time.Date(2024, 5, 1, 0, 20, 0, 0, time.UTC), 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if series.Cnt != 4 || series.Timezone != 28800 || series.Model != defaultEnsembleModel {
+		t.Fatalf("got %d entries, timezone %d, model %q", series.Cnt, series.Timezone, series.Model)
+	}
+
+	first := series.List[0]
+	want := EnsembleStats{Mean: 27, Median: 27, P10: 26.2, P25: 26.5, P75: 27.5, P90: 27.8}
+	if first.Members != 5 || first.Temp != want {
+		t.Errorf("first temp %+v from %d members, want %+v", first.Temp, first.Members, want)
+	}
+
+	if first.WindSpeed != (EnsembleStats{Mean: 10, Median: 10, P10: 10, P25: 10, P75: 10, P90: 10}) {
+		t.Errorf("first wind speed %+v", first.WindSpeed)
+	}
+
+	// a member without a value is left out rather than counted as zero
+	if got := series.List[1]; got.Members != 4 || got.Temp.Mean != 27.48 {
+		t.Errorf("second step %d members, mean %v", got.Members, got.Temp.Mean)
+	}
+
+	if got := series.List[2]; got.Pop != 0.4 || got.Precipitation.Median != 0.05 {
+		t.Errorf("third step pop %v, precipitation %+v", got.Pop, got.Precipitation)
+	}
+
+	calls := caller.calls()
+	if len(calls) != 1 || !strings.HasPrefix(calls[0], "https://ensemble-api.open-meteo.com/v1/ensemble?") {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+
+	u, _ := url.Parse(calls[0])
+	if q := u.Query(); q.Get("models") != defaultEnsembleModel || q.Get("forecast_days") != "15" || q.Get("timeformat") != "unixtime" {
+		t.Errorf("unexpected query %v", q)
+	}
+
+	// each member's three variables count, over 15 days
+	if got, want := p.QuotaUsage().Minute.Used, 51*3/10.0*15/14; math.Abs(got-want) > 1e-9 {
+		t.Errorf("quota used %v, want %v", got, want)
+	}
+
+	if _, err := p.GetEnsembleForecast(-8.72, 115.17, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC), 24); !errors.Is(err, ErrNoForecastAtTime) {
+		t.Errorf("err = %v, want ErrNoForecastAtTime", err)
+	}
+
+	if _, err := p.GetEnsembleForecast(-8.72, 115.17, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), 0); err == nil {
+		t.Error("expected error for zero hours")
+	}
+}
+
+func TestParser_EnsemblePop(t *testing.T) {
+	newParser := func(enabled bool) *Parser {
+		// forecasts come from the fake client, the ensemble from the caller
+		p, _ := newFakeParser(fakeEnsembleJSON)
+		p.SetEnsemblePop(enabled).SetProvenance(true)
+		p.om = &fakeOpenMeteo{}
+		return p
+	}
+
+	t.Run("Test forecast", func(t *testing.T) {
+		p := newParser(true)
+
+		forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if forecast.Pop != 0.4 {
+			t.Errorf("Pop = %v, want 0.4", forecast.Pop)
+		}
+
+		if got := forecast.Provenance["pop"]; got.Dt != 1714528800 || got.Offset != 3600 {
+			t.Errorf("pop provenance %+v", got)
+		}
+	})
+
+	t.Run("Test list", func(t *testing.T) {
+		p := newParser(true)
+
+		list, err := p.GetOpenWeatherForecastList(-8.68, 115.2, fakeNow(), 3*time.Hour, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// four of five members total at least 0.1 mm over the three hours
+		if got := list.List[0].Pop; got != 0.8 {
+			t.Errorf("Pop = %v, want 0.8", got)
+		}
+	})
+
+	t.Run("Test disabled", func(t *testing.T) {
+		p := newParser(false)
+
+		forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := forecast.sources["pop"]; ok || forecast.Pop != 0 {
+			t.Errorf("Pop = %v without SetEnsemblePop", forecast.Pop)
+		}
+
+		if calls := p.callApi.(*fakeCaller).calls(); len(calls) != 0 {
+			t.Errorf("unexpected calls %v", calls)
+		}
+	})
+}
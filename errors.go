@@ -0,0 +1,117 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoForecastAtTime is returned when the upstream data does not cover
+	// the requested time.
+	ErrNoForecastAtTime = errors.New("no forecast at the requested time")
+
+	// ErrInvalidCoordinates is returned for coordinates that are out of
+	// range or rejected by Open-Meteo.
+	ErrInvalidCoordinates = errors.New("invalid coordinates")
+
+	// ErrRateLimited matches an *UpstreamError with status 429.
+	ErrRateLimited = errors.New("rate limited by Open-Meteo")
+
+	// ErrDecodeResponse wraps failures to decode an Open-Meteo response.
+	ErrDecodeResponse = errors.New("cannot decode Open-Meteo response")
+
+	// ErrQuotaExceeded is returned when the client-side quota has no room
+	// for a request.
+	ErrQuotaExceeded = errors.New("client-side Open-Meteo quota exceeded")
+
+	// ErrCircuitOpen is returned without calling upstream while the circuit
+	// breaker is open.
+	ErrCircuitOpen = errors.New("circuit breaker open")
+
+	// ErrMissingTimestamp is returned when the nearest sample carries no
+	// time, rather than reporting it at the zero time.
+	ErrMissingTimestamp = errors.New("forecast sample has no timestamp")
+)
+
+// UpstreamError is a non-200 response from Open-Meteo. Reason carries the
+// "reason" field of Open-Meteo's error body and RetryAfter the Retry-After
+// header, when present.
+type UpstreamError struct {
+	StatusCode int
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *UpstreamError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("open-meteo: status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("open-meteo: status %d: %s", e.StatusCode, e.Reason)
+}
+
+func (e *UpstreamError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInvalidCoordinates:
+		reason := strings.ToLower(e.Reason)
+		return e.StatusCode == http.StatusBadRequest &&
+			(strings.Contains(reason, "latitude") || strings.Contains(reason, "longitude"))
+	}
+
+	return false
+}
+
+func newUpstreamError(statusCode int, body []byte) *UpstreamError {
+	var resp struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(body, &resp)
+
+	return &UpstreamError{StatusCode: statusCode, Reason: resp.Reason}
+}
+
+func validateCoordinates(lat, lon float64) error {
+	if math.IsNaN(lat) || math.IsNaN(lon) || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return fmt.Errorf("%w: %f,%f", ErrInvalidCoordinates, lat, lon)
+	}
+
+	return nil
+}
+
+// ToOpenWeatherError maps an error returned by Parser to OpenWeather's error
+// envelope. Cod holds the HTTP status OpenWeather would answer with.
+func ToOpenWeatherError(err error) *Error {
+	var owErr *Error
+	if errors.As(err, &owErr) {
+		return owErr
+	}
+
+	var upstream *UpstreamError
+
+	switch {
+	case errors.Is(err, ErrInvalidCoordinates):
+		return newError(http.StatusBadRequest, "wrong latitude or longitude")
+	case errors.Is(err, ErrNoForecastAtTime):
+		return newError(http.StatusNotFound, "no forecast for the requested time")
+	case errors.Is(err, ErrCircuitOpen):
+		return newError(http.StatusServiceUnavailable, "Service temporarily unavailable")
+	case errors.Is(err, ErrRateLimited), errors.Is(err, ErrQuotaExceeded):
+		return newError(http.StatusTooManyRequests, "Your account is temporary blocked due to exceeding of requests limitation of your subscription type.")
+	case errors.As(err, &upstream), errors.Is(err, ErrDecodeResponse), errors.Is(err, ErrMissingTimestamp):
+		return newError(http.StatusBadGateway, err.Error())
+	default:
+		return newError(http.StatusInternalServerError, "Internal error")
+	}
+}
+
+func newError(status int, message string) *Error {
+	return &Error{Cod: strconv.Itoa(status), Message: message}
+}
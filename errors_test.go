@@ -0,0 +1,138 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	go_http "github.com/saktibimantara/go-http"
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+func TestOpenMeteoClient_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      int
+		body      string
+		wantIs    []error
+		wantNotIs []error
+		wantCod   string
+	}{
+		{
+			name:    "Test rate limited",
+			code:    429,
+			body:    `{"error":true,"reason":"Minutely API request limit exceeded. Please try again in one minute."}`,
+			wantIs:  []error{ErrRateLimited},
+			wantCod: "429",
+		},
+		{
+			name:      "Test bad latitude",
+			code:      400,
+			body:      `{"error":true,"reason":"Latitude must be in range of -90 to 90°. Given: 91.0."}`,
+			wantIs:    []error{ErrInvalidCoordinates},
+			wantNotIs: []error{ErrRateLimited},
+			wantCod:   "400",
+		},
+		{
+			name:      "Test server error",
+			code:      502,
+			body:      `Bad Gateway`,
+			wantNotIs: []error{ErrRateLimited, ErrInvalidCoordinates},
+			wantCod:   "502",
+		},
+		{
+			name:    "Test decode failure",
+			code:    200,
+			body:    `{"hourly": "nope"}`,
+			wantIs:  []error{ErrDecodeResponse},
+			wantCod: "502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, caller := newFakeParser("")
+			caller.respond = func(url string) (*go_http.Response, error) {
+				return &go_http.Response{Code: tt.code, Data: []byte(tt.body)}, nil
+			}
+
+			_, err := p.GetOpenWeatherForecast(-8.68, 115.2, time.Now())
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			for _, target := range tt.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("errors.Is(%v, %v) = false", err, target)
+				}
+			}
+
+			for _, target := range tt.wantNotIs {
+				if errors.Is(err, target) {
+					t.Errorf("errors.Is(%v, %v) = true", err, target)
+				}
+			}
+
+			if tt.code != 200 {
+				var upstream *UpstreamError
+				if !errors.As(err, &upstream) || upstream.StatusCode != tt.code {
+					t.Errorf("errors.As(%v, *UpstreamError) failed", err)
+				}
+			}
+
+			if cod := ToOpenWeatherError(err).Cod; cod != tt.wantCod {
+				t.Errorf("Cod = %s, want %s", cod, tt.wantCod)
+			}
+		})
+	}
+}
+
+func TestForecastFromResponse_NoForecastAtTime(t *testing.T) {
+	var resp pom.ForecastResponse
+	if err := json.Unmarshal([]byte(fakeForecastJSON), &resp); err != nil {
+		t.Fatal(err)
+	}
+	prepareResponse(&resp)
+
+	tests := []struct {
+		name      string
+		startTime time.Time
+		wantErr   error
+	}{
+		{"Test inside range", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), nil},
+		{"Test before range", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), ErrNoForecastAtTime},
+		{"Test after range", time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC), ErrNoForecastAtTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parser{}.forecastFromResponse(&resp, tt.startTime)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToOpenWeatherError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantCod string
+	}{
+		{"Test city not found", fmt.Errorf("resolve: %w", ErrCityNotFound), "404"},
+		{"Test invalid coordinates", validateCoordinates(91, 0), "400"},
+		{"Test no forecast", ErrNoForecastAtTime, "404"},
+		{"Test unknown", errors.New("boom"), "500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cod := ToOpenWeatherError(tt.err).Cod; cod != tt.wantCod {
+				t.Errorf("Cod = %s, want %s", cod, tt.wantCod)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	go_http "github.com/saktibimantara/go-http"
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+const fakeForecastJSON = `{
+	"latitude": -8.68,
+	"longitude": 115.2,
+	"timezone": "GMT",
+	"utc_offset_seconds": 0,
+	"hourly": {
+		"time": ["2024-05-01T00:00", "2024-05-01T01:00", "2024-05-01T02:00"],
+		"temperature_2m": [27.1, 27.5, 28.0],
+		"wind_speed_10m": [10.2, 11.0, 12.5],
+		"wind_direction_10m": [90, 95, 100],
+		"wind_gusts_10m": [20.1, 21.0, 22.3],
+		"pressure_msl": [1010.2, 1010.8, 1011.4],
+		"surface_pressure": [1005.1, 1005.6, 1006.2],
+		"rain": [0, 0.2, 1.1],
+		"weather_code": [1, 3, 61],
+		"is_day": [0, 0, 1],
+		"shortwave_radiation": [0, 0, 35.5]
+	},
+	"daily": {
+		"time": ["2024-05-01"],
+		"temperature_2m_max": [31.2],
+		"temperature_2m_min": [24.3],
+		"weather_code": [61],
+		"uv_index_max": [9.6],
+		"shortwave_radiation_sum": [22.4],
+		"sunshine_duration": [31500]
+	}
+}`
+
+// fakeNow is the clock for tests using the canned responses above, so their
+// start times are not routed to the archive.
+func fakeNow() time.Time {
+	return time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// fakeCaller is a go_http.CallAPI returning canned responses.
+type fakeCaller struct {
+	mu      sync.Mutex
+	urls    []string
+	respond func(url string) (*go_http.Response, error)
+}
+
+func (f *fakeCaller) Get(url string) (*go_http.Response, error) {
+	f.mu.Lock()
+	f.urls = append(f.urls, url)
+	f.mu.Unlock()
+
+	return f.respond(url)
+}
+
+func (f *fakeCaller) Post(url string, body go_http.Body) (*go_http.Response, error) {
+	return nil, fmt.Errorf("unexpected POST %s", url)
+}
+
+func (f *fakeCaller) Put(url string, body go_http.Body) (*go_http.Response, error) {
+	return nil, fmt.Errorf("unexpected PUT %s", url)
+}
+
+func (f *fakeCaller) Delete(url string) (*go_http.Response, error) {
+	return nil, fmt.Errorf("unexpected DELETE %s", url)
+}
+
+// newFakeParser returns a parser on the fake clock whose requests, through
+// the Open-Meteo client or fetched directly, are all answered with body.
+// Tests needing other responses replace the caller's respond.
+func newFakeParser(body string) (*Parser, *fakeCaller) {
+	caller := &fakeCaller{
+		respond: func(url string) (*go_http.Response, error) {
+			return &go_http.Response{Code: 200, Data: []byte(body)}, nil
+		},
+	}
+
+	p := NewParser("xxx", "https://ddd.cloudfront.net")
+	p.callApi = caller
+	p.om = newOpenMeteoClient(p.config, caller)
+	p.now = fakeNow
+	return p, caller
+}
+
+func (f *fakeCaller) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.urls...)
+}
+
+// repeatJSON returns a JSON array holding n copies of obj.
+func repeatJSON(obj string, n int) []byte {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = obj
+	}
+
+	return []byte("[" + strings.Join(parts, ",") + "]")
+}
+
+const fakeAQIJSON = `{
+	"latitude": -8.68,
+	"longitude": 115.2,
+	"timezone": "GMT",
+	"hourly": {
+		"time": ["2024-05-01T00:00", "2024-05-01T01:00"],
+		"pm10": [20.5, 22.1],
+		"pm2_5": [10.2, 11.8],
+		"carbon_monoxide": [210, 220],
+		"nitrogen_dioxide": [5.1, 6.2],
+		"sulphur_dioxide": [2.1, 2.4],
+		"ozone": [40, 45],
+		"uv_index": [0, 0.5],
+		"us_aqi": [42, 45]
+	}
+}`
+
+// fakeOpenMeteo is a pom.IGoOpenMeteo serving canned responses. Forecast
+// calls fail with forecastErrs in order, then with forecastErr if set. When
+// gate is set, calls block until it is closed.
+type fakeOpenMeteo struct {
+	gate          chan struct{}
+	mu            sync.Mutex
+	forecastCalls int
+	aqiCalls      int
+	forecastErrs  []error
+	forecastErr   error
+	aqiErr        error
+}
+
+func (f *fakeOpenMeteo) Forecast(param pom.IForecastParams) (*pom.ForecastResponse, error) {
+	f.mu.Lock()
+	f.forecastCalls++
+	err := f.forecastErr
+	if len(f.forecastErrs) > 0 {
+		err, f.forecastErrs = f.forecastErrs[0], f.forecastErrs[1:]
+	}
+	f.mu.Unlock()
+
+	if f.gate != nil {
+		<-f.gate
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pom.ForecastResponse
+	if err := json.Unmarshal([]byte(fakeForecastJSON), &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (f *fakeOpenMeteo) GetAQI(param pom.IForecastParams) (*pom.AQIResponse, error) {
+	f.mu.Lock()
+	f.aqiCalls++
+	err := f.aqiErr
+	f.mu.Unlock()
+
+	if f.gate != nil {
+		<-f.gate
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pom.AQIResponse
+	if err := json.Unmarshal([]byte(fakeAQIJSON), &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (f *fakeOpenMeteo) calls() (forecast, aqi int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.forecastCalls, f.aqiCalls
+}
@@ -0,0 +1,109 @@
+package open_meteo_parser
+
+import (
+	"fmt"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+// GetOpenWeatherForecastList returns cnt forecasts spaced step apart from
+// startTime, shaped like OpenWeather's 5 day / 3 hour forecast. All entries
+// come from a single upstream request.
+func (p Parser) GetOpenWeatherForecastList(latitude, longitude float64, startTime time.Time, step time.Duration, cnt int) (*Response3HoursStepForecast, error) {
+	if cnt < 1 || step <= 0 {
+		return nil, fmt.Errorf("invalid forecast list: cnt %d, step %s", cnt, step)
+	}
+
+	openResp, err := p.fetchForecast(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response3HoursStepForecast{
+		Cod:  "200",
+		List: make([]Forecast, 0, cnt),
+	}
+
+	// the UV index is optional, so the list is served without it when the
+	// air quality request fails
+	var aqiResp *pom.AQIResponse
+	if p.uvIndex {
+		aqiResp, _ = p.fetchAQI(latitude, longitude)
+	}
+
+	// so is the ensemble's probability of precipitation
+	var ens *ensembleResponse
+	if p.ensemblePop {
+		ens, _ = p.fetchEnsemble(latitude, longitude)
+	}
+
+	var main Main
+	for i := 0; i < cnt; i++ {
+		at := startTime.Add(time.Duration(i) * step)
+		forecast, err := p.forecastFromResponse(openResp, at)
+		if err != nil {
+			return nil, err
+		}
+
+		if aqiResp != nil {
+			p.fillUV(forecast, aqiResp, at)
+		}
+
+		if ens != nil {
+			ens.fillPop(forecast, at, step)
+		}
+
+		forecast.aggregateWind(openResp, at, step)
+
+		if i == 0 {
+			p.fillLocation(forecast, latitude, longitude)
+			resp.City = *forecast.City
+			main = forecast.Main
+		}
+
+		forecast.City = nil
+		forecast.Main.Location = main.Location
+		forecast.Main.Lat = main.Lat
+		forecast.Main.Lng = main.Lng
+
+		resp.List = append(resp.List, *forecast)
+	}
+
+	resp.Cnt = len(resp.List)
+
+	return resp, nil
+}
+
+// GetOpenWeatherAQIForecast returns the hourly air quality forecast from
+// startTime onwards, shaped like OpenWeather's air pollution forecast.
+func (p Parser) GetOpenWeatherAQIForecast(latitude, longitude float64, startTime time.Time) (*ResponseAQI, error) {
+	aqiResp, err := p.fetchAQI(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ResponseAQI{
+		Coord: Coord{Lat: aqiResp.Latitude, Lon: aqiResp.Longitude},
+	}
+
+	if aqiResp.Hourly == nil {
+		return resp, nil
+	}
+
+	from := startTime.Truncate(time.Hour)
+	for _, t := range aqiResp.Hourly.Time {
+		if t.Time.Before(from) {
+			continue
+		}
+
+		aqi, err := p.aqiFromResponse(aqiResp, t.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.List = append(resp.List, *aqi)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,32 @@
+package open_meteo_parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParser_GetOpenWeatherForecastList(t *testing.T) {
+	om := &fakeOpenMeteo{}
+	p := NewParser("xxx", "https://ddd.cloudfront.net")
+	p.om = om
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	resp, err := p.GetOpenWeatherForecastList(-8.68, 115.2, start, time.Hour, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Cnt != 3 || len(resp.List) != 3 {
+		t.Fatalf("got %d entries, want 3", len(resp.List))
+	}
+
+	for i, want := range []float64{27.1, 27.5, 28.0} {
+		if got := resp.List[i].Main.Temp; got != want {
+			t.Errorf("entry %d: temp = %v, want %v", i, got, want)
+		}
+	}
+
+	if forecastCalls, _ := om.calls(); forecastCalls != 1 {
+		t.Errorf("got %d upstream calls, want 1", forecastCalls)
+	}
+}
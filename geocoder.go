@@ -0,0 +1,168 @@
+package open_meteo_parser
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	go_http "github.com/saktibimantara/go-http"
+)
+
+type Place struct {
+	ID         int
+	Name       string
+	Country    string
+	Latitude   float64
+	Longitude  float64
+	Population int
+	Timezone   string
+}
+
+// ReverseGeocoder resolves coordinates to the nearest named place.
+type ReverseGeocoder interface {
+	ReverseGeocode(latitude, longitude float64) (*Place, error)
+}
+
+// Geocoder resolves a city name, optionally restricted to a country given as
+// an ISO 3166 alpha-2 code, to candidate places.
+type Geocoder interface {
+	Geocode(name, country string) ([]Place, error)
+}
+
+// SetReverseGeocoder sets the geocoder used to fill City name and country.
+// Geocoding is best effort: a failed lookup leaves those fields empty.
+func (p *Parser) SetReverseGeocoder(geocoder ReverseGeocoder) *Parser {
+	p.geocoder = geocoder
+	return p
+}
+
+func (p Parser) fillLocation(forecast *Forecast, lat, lon float64) {
+	var place *Place
+	if p.geocoder != nil {
+		if found, err := p.geocoder.ReverseGeocode(lat, lon); err == nil {
+			place = found
+		}
+	}
+
+	fillPlace(forecast, lat, lon, place)
+}
+
+// fillPlace sets the forecast's coordinates and, when known, its place.
+func fillPlace(forecast *Forecast, lat, lon float64, place *Place) {
+	forecast.Main.Lat = strconv.FormatFloat(lat, 'f', -1, 64)
+	forecast.Main.Lng = strconv.FormatFloat(lon, 'f', -1, 64)
+
+	if forecast.City == nil {
+		forecast.City = &City{}
+	}
+
+	if place != nil {
+		setPlace(forecast, place)
+	}
+}
+
+func setPlace(forecast *Forecast, place *Place) {
+	if forecast.City == nil {
+		forecast.City = &City{}
+	}
+
+	forecast.Main.Location = place.Name
+	forecast.City.setPlace(place)
+}
+
+func (c *City) setPlace(place *Place) {
+	c.ID = place.ID
+	c.Name = place.Name
+	c.Country = place.Country
+	c.Population = place.Population
+}
+
+// SetGeocoder sets the geocoder used by the ByCity methods. The Open-Meteo
+// geocoding API is used when none is set.
+func (p *Parser) SetGeocoder(geocoder Geocoder) *Parser {
+	p.cityGeocoder = geocoder
+	return p
+}
+
+const defaultGeocodingURL = "https://geocoding-api.open-meteo.com/v1/search"
+
+// OpenMeteoGeocoder resolves names with the Open-Meteo geocoding API.
+type OpenMeteoGeocoder struct {
+	URL      string
+	Language string
+	callApi  go_http.CallAPI
+}
+
+func NewOpenMeteoGeocoder() *OpenMeteoGeocoder {
+	return newOpenMeteoGeocoder(go_http.New(&go_http.Config{}))
+}
+
+func newOpenMeteoGeocoder(callApi go_http.CallAPI) *OpenMeteoGeocoder {
+	return &OpenMeteoGeocoder{
+		URL:      defaultGeocodingURL,
+		Language: "en",
+		callApi:  callApi,
+	}
+}
+
+type geocodingResponse struct {
+	Results []struct {
+		ID          int     `json:"id"`
+		Name        string  `json:"name"`
+		Latitude    float64 `json:"latitude"`
+		Longitude   float64 `json:"longitude"`
+		CountryCode string  `json:"country_code"`
+		Timezone    string  `json:"timezone"`
+		Population  int     `json:"population"`
+	} `json:"results"`
+}
+
+func (g *OpenMeteoGeocoder) Geocode(name, country string) ([]Place, error) {
+	query := url.Values{}
+	query.Set("name", name)
+	query.Set("count", "10")
+	query.Set("language", g.Language)
+	query.Set("format", "json")
+
+	var resp geocodingResponse
+	if err := fetchJSON(g.callApi, g.URL+"?"+query.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	var places []Place
+	for _, r := range resp.Results {
+		if country != "" && !strings.EqualFold(r.CountryCode, country) {
+			continue
+		}
+
+		places = append(places, Place{
+			ID:         r.ID,
+			Name:       r.Name,
+			Country:    r.CountryCode,
+			Latitude:   r.Latitude,
+			Longitude:  r.Longitude,
+			Population: r.Population,
+			Timezone:   r.Timezone,
+		})
+	}
+
+	return places, nil
+}
+
+const earthRadiusKm = 6371.0
+
+// distanceKm returns the great-circle distance between two points.
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
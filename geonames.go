@@ -0,0 +1,152 @@
+package open_meteo_parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GeoNamesGeocoder resolves places offline from a GeoNames cities dump such
+// as cities15000.txt (https://download.geonames.org/export/dump/). A small
+// dump can be embedded with go:embed for tests and air-gapped deployments.
+type GeoNamesGeocoder struct {
+	places []geoNamesPlace
+}
+
+type geoNamesPlace struct {
+	Place
+	names []string
+}
+
+// NewGeoNamesGeocoder reads a tab-separated GeoNames dump.
+func NewGeoNamesGeocoder(r io.Reader) (*GeoNamesGeocoder, error) {
+	var places []geoNamesPlace
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		place, err := parseGeoNamesLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("geonames line %d: %w", line, err)
+		}
+
+		places = append(places, place)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &GeoNamesGeocoder{places: places}, nil
+}
+
+// LoadGeoNamesGeocoder reads a GeoNames dump from path.
+func LoadGeoNamesGeocoder(path string) (*GeoNamesGeocoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewGeoNamesGeocoder(f)
+}
+
+// geoNamesColumns is the number of columns in a GeoNames dump: geonameid,
+// name, asciiname, alternatenames, latitude, longitude, feature class,
+// feature code, country code, cc2, admin1-4, population, elevation, dem,
+// timezone and modification date.
+const geoNamesColumns = 19
+
+func parseGeoNamesLine(text string) (geoNamesPlace, error) {
+	fields := strings.Split(text, "\t")
+	if len(fields) < geoNamesColumns {
+		return geoNamesPlace{}, fmt.Errorf("expected %d columns, got %d", geoNamesColumns, len(fields))
+	}
+
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return geoNamesPlace{}, err
+	}
+
+	lat, err := strconv.ParseFloat(fields[4], 64)
+	if err != nil {
+		return geoNamesPlace{}, err
+	}
+
+	lon, err := strconv.ParseFloat(fields[5], 64)
+	if err != nil {
+		return geoNamesPlace{}, err
+	}
+
+	population, _ := strconv.Atoi(fields[14])
+
+	names := []string{fields[1], fields[2]}
+	if fields[3] != "" {
+		names = append(names, strings.Split(fields[3], ",")...)
+	}
+
+	return geoNamesPlace{
+		Place: Place{
+			ID:         id,
+			Name:       fields[1],
+			Country:    fields[8],
+			Latitude:   lat,
+			Longitude:  lon,
+			Population: population,
+			Timezone:   fields[17],
+		},
+		names: names,
+	}, nil
+}
+
+func (g *GeoNamesGeocoder) ReverseGeocode(latitude, longitude float64) (*Place, error) {
+	nearest := -1
+	minDist := math.MaxFloat64
+
+	for i, place := range g.places {
+		dist := distanceKm(latitude, longitude, place.Latitude, place.Longitude)
+		if dist < minDist {
+			minDist = dist
+			nearest = i
+		}
+	}
+
+	if nearest < 0 {
+		return nil, fmt.Errorf("no place found near %f,%f", latitude, longitude)
+	}
+
+	place := g.places[nearest].Place
+	return &place, nil
+}
+
+func (g *GeoNamesGeocoder) Geocode(name, country string) ([]Place, error) {
+	var matches []Place
+
+	for _, place := range g.places {
+		if country != "" && !strings.EqualFold(place.Country, country) {
+			continue
+		}
+
+		for _, n := range place.names {
+			if strings.EqualFold(n, name) {
+				matches = append(matches, place.Place)
+				break
+			}
+		}
+	}
+
+	return matches, nil
+}
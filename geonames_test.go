@@ -0,0 +1,62 @@
+package open_meteo_parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const testGeoNamesDump = "1645528\tDenpasar\tDenpasar\t\t-8.65\t115.21667\tP\tPPLA\tID\t\t02\t\t\t\t405923\t\t20\tAsia/Makassar\t2023-01-01\n" +
+	"2643743\tLondon\tLondon\t\t51.50853\t-0.12574\tP\tPPLC\tGB\t\tENG\t\t\t\t8961989\t\t25\tEurope/London\t2023-01-01\n"
+
+func TestGeoNamesGeocoder_ReverseGeocode(t *testing.T) {
+	g, err := NewGeoNamesGeocoder(strings.NewReader(testGeoNamesDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		lat, lon    float64
+		wantName    string
+		wantCountry string
+	}{
+		{"Test Bali", -8.68163896537287, 115.19724863873421, "Denpasar", "ID"},
+		{"Test London", 51.47, -0.45, "London", "GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser("xxx", "https://ddd.cloudfront.net").SetReverseGeocoder(g)
+
+			forecast := &Forecast{}
+			p.fillLocation(forecast, tt.lat, tt.lon)
+
+			if forecast.City.Name != tt.wantName || forecast.City.Country != tt.wantCountry {
+				t.Errorf("got %s, %s, want %s, %s", forecast.City.Name, forecast.City.Country, tt.wantName, tt.wantCountry)
+			}
+
+			if forecast.Main.Location != tt.wantName {
+				t.Errorf("Main.Location = %q, want %q", forecast.Main.Location, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParseGeoNamesLine(t *testing.T) {
+	line := strings.TrimSuffix(strings.SplitAfter(testGeoNamesDump, "\n")[0], "\n")
+
+	place, err := parseGeoNamesLine(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if place.Name != "Denpasar" || place.Timezone != "Asia/Makassar" || place.Population != 405923 {
+		t.Errorf("unexpected place %+v", place.Place)
+	}
+
+	// a line missing the modification date is truncated
+	short := line[:strings.LastIndex(line, "\t")]
+	if _, err := parseGeoNamesLine(short); err == nil || !strings.Contains(err.Error(), "expected 19 columns, got 18") {
+		t.Errorf("err = %v, want a column count error", err)
+	}
+}
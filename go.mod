@@ -3,6 +3,7 @@ module github.com/saktibimantara/open-meteo-parser
 go 1.21.0
 
 require (
-	github.com/saktibimantara/go-http v0.0.3 // indirect
-	github.com/saktibimantara/go-open-meteo v0.0.14 // indirect
+	github.com/saktibimantara/go-http v0.0.3
+	github.com/saktibimantara/go-open-meteo v0.0.14
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,142 @@
+package open_meteo_parser
+
+import (
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+const (
+	archivePath = "/v1/archive"
+
+	// archiveDelay is how far the archive's reanalysis data lags behind real
+	// time. More recent past times are served by the forecast API's
+	// past_days, which also keeps the minutely15 series.
+	archiveDelay = 5 * 24 * time.Hour
+
+	// recentPast is how old a start time must be before the forecast
+	// request asks for past days.
+	recentPast = time.Hour
+
+	archiveDateLayout = "2006-01-02"
+)
+
+// GetOpenWeatherHistorical returns the weather at a past time from the
+// Open-Meteo archive (ERA5 reanalysis), which runs about five days behind.
+// The forecast has the same shape as GetOpenWeatherForecast, without
+// minutely15 data.
+func (p Parser) GetOpenWeatherHistorical(latitude, longitude float64, startTime time.Time) (*Forecast, error) {
+	openResp, err := p.fetchArchive(latitude, longitude, startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := p.forecastFromResponse(openResp, startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	p.fillLocation(forecast, latitude, longitude)
+
+	return forecast, nil
+}
+
+// fetchPastForecast fetches the forecast including enough past days to
+// cover startTime.
+func (p Parser) fetchPastForecast(lat, lon float64, startTime time.Time) (*pom.ForecastResponse, error) {
+	params, err := p.forecastParams(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	// one extra day covers the offset between UTC and the local day
+	days := int(math.Ceil(p.clock().Sub(startTime).Hours()/24)) + 1
+
+	query := params.(queryParams)
+	query.extra.Set("past_days", strconv.Itoa(days))
+
+	return p.fetchForecastParams(query)
+}
+
+func (p Parser) fetchArchive(lat, lon float64, startTime time.Time) (*pom.ForecastResponse, error) {
+	params, err := p.forecastParams(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := url.ParseQuery(params.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	// the archive has no minutely15 series or UV index and serves
+	// reanalysis rather than the forecast models over a date range instead
+	// of forecast and past days; a day either side covers the location's
+	// timezone
+	day := startTime.UTC()
+	query.Del("minutely_15")
+	query.Del("models")
+	query.Del("forecast_days")
+	query.Del("past_days")
+	removeVariables(query, "daily", string(pom.DailyUvIndexMax))
+	query.Set("start_date", day.AddDate(0, 0, -1).Format(archiveDateLayout))
+	query.Set("end_date", day.AddDate(0, 0, 1).Format(archiveDateLayout))
+
+	key := p.apiURL("archive", archivePath) + "?" + query.Encode()
+	resp, err := p.flights.do(key, func() (any, error) {
+		var data []byte
+		done := p.start(OperationFetchForecast)
+		err := p.call(requestWeight(query), func() (err error) {
+			data, err = fetch(p.callApi, key)
+			return err
+		})
+		done(err)
+		if err != nil {
+			return nil, err
+		}
+
+		responses, err := decodeForecastResponses(data)
+		if err != nil {
+			return nil, err
+		}
+
+		prepareResponse(&responses[0])
+		return &responses[0], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pom.ForecastResponse), nil
+}
+
+// removeVariables drops variables from the comma-separated list under key,
+// and the key itself once its list is empty.
+func removeVariables(query url.Values, key string, variables ...string) {
+	var kept []string
+	for _, v := range strings.Split(query.Get(key), ",") {
+		if v != "" && !slices.Contains(variables, v) {
+			kept = append(kept, v)
+		}
+	}
+
+	if len(kept) == 0 {
+		query.Del(key)
+		return
+	}
+
+	query.Set(key, strings.Join(kept, ","))
+}
+
+func (p Parser) clock() time.Time {
+	if p.now == nil {
+		return time.Now()
+	}
+
+	return p.now()
+}
@@ -0,0 +1,107 @@
+package open_meteo_parser
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParser_HistoricalRouting(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantHost  string
+		wantQuery map[string]string
+		// notWant are variables the upstream does not serve
+		notWant []string
+	}{
+		{
+			name:      "Test forecast window",
+			now:       time.Date(2024, 5, 1, 0, 30, 0, 0, time.UTC),
+			wantHost:  "api.open-meteo.com",
+			wantQuery: map[string]string{"past_days": ""},
+		},
+		{
+			name:      "Test recent past uses past days",
+			now:       time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC),
+			wantHost:  "api.open-meteo.com",
+			wantQuery: map[string]string{"past_days": "4"},
+		},
+		{
+			name:     "Test archive",
+			now:      time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			wantHost: "archive-api.open-meteo.com",
+			wantQuery: map[string]string{
+				"start_date":    "2024-04-30",
+				"end_date":      "2024-05-02",
+				"minutely_15":   "",
+				"forecast_days": "",
+				"past_days":     "",
+			},
+			notWant: []string{"uv_index_max"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, caller := newFakeParser(fakeForecastJSON)
+			p.now = func() time.Time { return tt.now }
+
+			forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if forecast.Main.Temp != 27.5 || forecast.Dt != int(startTime.Unix()) {
+				t.Errorf("unexpected forecast %+v", forecast)
+			}
+
+			calls := caller.calls()
+			if len(calls) != 1 {
+				t.Fatalf("got %d upstream calls, want 1", len(calls))
+			}
+
+			u, err := url.Parse(calls[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if u.Host != tt.wantHost {
+				t.Errorf("host = %s, want %s", u.Host, tt.wantHost)
+			}
+
+			for key, want := range tt.wantQuery {
+				if got := u.Query().Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+
+			daily := strings.Split(u.Query().Get("daily"), ",")
+			for _, variable := range tt.notWant {
+				if slices.Contains(daily, variable) {
+					t.Errorf("%s requested in daily %v", variable, daily)
+				}
+			}
+
+			if !slices.Contains(daily, "temperature_2m_max") {
+				t.Errorf("daily %v lost the other variables", daily)
+			}
+		})
+	}
+}
+
+func TestRequestWeight_DateRange(t *testing.T) {
+	query := url.Values{
+		"hourly":     {"temperature_2m"},
+		"start_date": {"2024-01-01"},
+		"end_date":   {"2024-01-28"},
+	}
+
+	if got := requestWeight(query); got != 2 {
+		t.Errorf("requestWeight() = %v, want 2", got)
+	}
+}
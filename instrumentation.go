@@ -0,0 +1,87 @@
+package open_meteo_parser
+
+// Operation is a stage of a parser request reported to Instrumentation.
+type Operation string
+
+const (
+	OperationFetchForecast Operation = "fetch_forecast"
+	OperationFetchAQI      Operation = "fetch_aqi"
+	OperationFetchMarine   Operation = "fetch_marine"
+	OperationFetchEnsemble Operation = "fetch_ensemble"
+	OperationLookup        Operation = "nearest_lookup"
+	OperationParse         Operation = "parse"
+	OperationAQI           Operation = "aqi_calculation"
+)
+
+// Resolution is the Open-Meteo series a forecast value was taken from.
+type Resolution string
+
+const (
+	ResolutionMinutely15 Resolution = "minutely_15"
+	ResolutionHourly     Resolution = "hourly"
+	ResolutionDaily      Resolution = "daily"
+)
+
+// Instrumentation observes the work a Parser does. Start is called when an
+// operation begins and the function it returns when the operation ends, with
+// its error. Fallback reports a forecast field taken from a coarser
+// resolution than the one preferred for it. Implementations must be safe
+// for concurrent use.
+type Instrumentation interface {
+	Start(op Operation) func(err error)
+	Fallback(field string, from, to Resolution)
+}
+
+// SetInstrumentation sets the instrumentation notified of upstream fetches,
+// nearest-time lookups, parsing and AQI calculation.
+func (p *Parser) SetInstrumentation(instrumentation Instrumentation) *Parser {
+	p.instrumentation = instrumentation
+	return p
+}
+
+func (p Parser) start(op Operation) func(err error) {
+	if p.instrumentation == nil {
+		return func(error) {}
+	}
+
+	return p.instrumentation.Start(op)
+}
+
+// preferredResolution is the finest series each forecast field is read from.
+var preferredResolution = map[string]Resolution{
+	"dt":              ResolutionMinutely15,
+	"main.temp":       ResolutionMinutely15,
+	"main.feels_like": ResolutionMinutely15,
+	"main.humidity":   ResolutionMinutely15,
+	"weather":         ResolutionMinutely15,
+	"wind.speed":      ResolutionMinutely15,
+	"wind.deg":        ResolutionMinutely15,
+	"wind.gust":       ResolutionMinutely15,
+	"main.pressure":   ResolutionHourly,
+	"main.sea_level":  ResolutionHourly,
+	"main.grnd_level": ResolutionHourly,
+	"rain.3h":         ResolutionHourly,
+	"main.temp_max":   ResolutionDaily,
+	"main.temp_min":   ResolutionDaily,
+
+	"solar.radiation":         ResolutionMinutely15,
+	"solar.radiation_sum":     ResolutionDaily,
+	"solar.sunshine_duration": ResolutionDaily,
+	"uv.max":                  ResolutionDaily,
+}
+
+// fieldSources records the resolution each populated forecast field was
+// taken from, keyed by its OpenWeather JSON path.
+type fieldSources map[string]Resolution
+
+func (p Parser) reportFallbacks(sources fieldSources) {
+	if p.instrumentation == nil {
+		return
+	}
+
+	for field, res := range sources {
+		if preferred, ok := preferredResolution[field]; ok && res != preferred {
+			p.instrumentation.Fallback(field, preferred, res)
+		}
+	}
+}
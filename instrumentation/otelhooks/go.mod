@@ -0,0 +1,25 @@
+module github.com/saktibimantara/open-meteo-parser/instrumentation/otelhooks
+
+go 1.21.0
+
+require (
+	github.com/saktibimantara/open-meteo-parser v0.0.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/metric v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/saktibimantara/go-http v0.0.3 // indirect
+	github.com/saktibimantara/go-open-meteo v0.0.14 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// the adapter is developed against the parser in this repository; pin a
+// tagged parser release here before tagging the adapter
+replace github.com/saktibimantara/open-meteo-parser => ../..
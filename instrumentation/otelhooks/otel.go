@@ -0,0 +1,83 @@
+// Package otelhooks reports parser instrumentation as OpenTelemetry spans and
+// a fallback counter. It lives in its own module so the parser itself does
+// not depend on OpenTelemetry.
+//
+//	parser.SetInstrumentation(otelhooks.New(otel.GetTracerProvider(), otel.GetMeterProvider()))
+//
+// Spans are roots unless the hooks carry a parent, as when serving a
+// request traced by the caller:
+//
+//	p := *parser
+//	p.SetInstrumentation(hooks.WithContext(r.Context()))
+package otelhooks
+
+import (
+	"context"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/saktibimantara/open-meteo-parser"
+
+var _ omp.Instrumentation = (*Hooks)(nil)
+
+// Hooks implements omp.Instrumentation. Each operation becomes a span named
+// "open_meteo_parser.<operation>"; fallbacks are counted by
+// open_meteo_parser.fallbacks with field, from and to attributes.
+type Hooks struct {
+	tracer    trace.Tracer
+	fallbacks metric.Int64Counter
+	parent    context.Context
+}
+
+func New(tp trace.TracerProvider, mp metric.MeterProvider) *Hooks {
+	h := &Hooks{tracer: tp.Tracer(instrumentationName), parent: context.Background()}
+
+	counter, err := mp.Meter(instrumentationName).Int64Counter(
+		"open_meteo_parser.fallbacks",
+		metric.WithDescription("Forecast fields taken from a coarser resolution than preferred."),
+	)
+	if err == nil {
+		h.fallbacks = counter
+	}
+
+	return h
+}
+
+// WithContext returns a copy of h whose spans are children of the span in
+// ctx, and whose fallbacks are counted with ctx.
+func (h *Hooks) WithContext(ctx context.Context) *Hooks {
+	c := *h
+	c.parent = ctx
+	return &c
+}
+
+func (h *Hooks) Start(op omp.Operation) func(err error) {
+	_, span := h.tracer.Start(h.parent, "open_meteo_parser."+string(op),
+		trace.WithAttributes(attribute.String("open_meteo_parser.operation", string(op))),
+	)
+
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (h *Hooks) Fallback(field string, from, to omp.Resolution) {
+	if h.fallbacks == nil {
+		return
+	}
+
+	h.fallbacks.Add(h.parent, 1, metric.WithAttributes(
+		attribute.String("field", field),
+		attribute.String("from", string(from)),
+		attribute.String("to", string(to)),
+	))
+}
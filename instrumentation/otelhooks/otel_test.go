@@ -0,0 +1,63 @@
+package otelhooks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHooks_Start(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	h := New(tp, noop.NewMeterProvider())
+
+	h.Start(omp.OperationParse)(nil)
+	h.Start(omp.OperationFetchForecast)(errors.New("boom"))
+	h.Fallback("main.temp", omp.ResolutionMinutely15, omp.ResolutionHourly)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	if got := spans[0].Name(); got != "open_meteo_parser.parse" {
+		t.Errorf("span name = %q", got)
+	}
+
+	if got := spans[1].Status().Code; got != codes.Error {
+		t.Errorf("fetch span status = %v, want error", got)
+	}
+}
+
+func TestHooks_WithContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	h := New(tp, noop.NewMeterProvider())
+
+	h.WithContext(ctx).Start(omp.OperationFetchForecast)(nil)
+	h.Start(omp.OperationParse)(nil)
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+
+	if got := spans[0].Parent().SpanID(); got != parent.SpanContext().SpanID() {
+		t.Errorf("fetch span parent = %v, want %v", got, parent.SpanContext().SpanID())
+	}
+
+	// the original hooks are unaffected
+	if spans[1].Parent().IsValid() {
+		t.Errorf("parse span has parent %v", spans[1].Parent().SpanID())
+	}
+}
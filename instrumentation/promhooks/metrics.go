@@ -0,0 +1,238 @@
+// Package promhooks records parser instrumentation as Prometheus counters and
+// histograms and serves them in the Prometheus text exposition format,
+// without depending on the Prometheus client library.
+//
+//	metrics := promhooks.New()
+//	parser.SetInstrumentation(metrics)
+//	http.Handle("/metrics", metrics)
+package promhooks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+)
+
+const namespace = "open_meteo_parser"
+
+// DefaultBuckets are the duration histogram buckets in seconds, the same as
+// the Prometheus client's defaults.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var _ omp.Instrumentation = (*Metrics)(nil)
+
+// Metrics implements omp.Instrumentation. It exposes:
+//
+//	open_meteo_parser_operations_total{operation,result}
+//	open_meteo_parser_operation_duration_seconds{operation}
+//	open_meteo_parser_fallbacks_total{field,from,to}
+type Metrics struct {
+	buckets []float64
+	now     func() time.Time
+
+	mu         sync.Mutex
+	operations map[operationKey]uint64
+	durations  map[omp.Operation]*histogram
+	fallbacks  map[fallbackKey]uint64
+}
+
+type operationKey struct {
+	op     omp.Operation
+	result string
+}
+
+type fallbackKey struct {
+	field    string
+	from, to omp.Resolution
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func New() *Metrics {
+	return NewWithBuckets(DefaultBuckets)
+}
+
+// NewWithBuckets uses the given upper bounds, in seconds, for the duration
+// histogram.
+func NewWithBuckets(buckets []float64) *Metrics {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Metrics{
+		buckets:    buckets,
+		now:        time.Now,
+		operations: make(map[operationKey]uint64),
+		durations:  make(map[omp.Operation]*histogram),
+		fallbacks:  make(map[fallbackKey]uint64),
+	}
+}
+
+func (m *Metrics) Start(op omp.Operation) func(err error) {
+	start := m.now()
+
+	return func(err error) {
+		elapsed := m.now().Sub(start).Seconds()
+
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.operations[operationKey{op: op, result: result}]++
+
+		h, ok := m.durations[op]
+		if !ok {
+			h = &histogram{counts: make([]uint64, len(m.buckets))}
+			m.durations[op] = h
+		}
+
+		for i, bound := range m.buckets {
+			if elapsed <= bound {
+				h.counts[i]++
+			}
+		}
+		h.count++
+		h.sum += elapsed
+	}
+}
+
+func (m *Metrics) Fallback(field string, from, to omp.Resolution) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fallbacks[fallbackKey{field: field, from: from, to: to}]++
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+
+	m.mu.Lock()
+	m.writeOperations(cw)
+	m.writeDurations(cw)
+	m.writeFallbacks(cw)
+	m.mu.Unlock()
+
+	if err := bw.Flush(); err != nil && cw.err == nil {
+		cw.err = err
+	}
+
+	return cw.n, cw.err
+}
+
+func (m *Metrics) writeOperations(w *countingWriter) {
+	name := namespace + "_operations_total"
+	w.printf("# HELP %s Parser operations by result.\n# TYPE %s counter\n", name, name)
+
+	keys := make([]operationKey, 0, len(m.operations))
+	for k := range m.operations {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].op != keys[j].op {
+			return keys[i].op < keys[j].op
+		}
+		return keys[i].result < keys[j].result
+	})
+
+	for _, k := range keys {
+		w.printf("%s{operation=%s,result=%s} %d\n", name, quote(string(k.op)), quote(k.result), m.operations[k])
+	}
+}
+
+func (m *Metrics) writeDurations(w *countingWriter) {
+	name := namespace + "_operation_duration_seconds"
+	w.printf("# HELP %s Parser operation duration.\n# TYPE %s histogram\n", name, name)
+
+	ops := make([]omp.Operation, 0, len(m.durations))
+	for op := range m.durations {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+
+	for _, op := range ops {
+		h := m.durations[op]
+		label := quote(string(op))
+
+		for i, bound := range m.buckets {
+			w.printf("%s_bucket{operation=%s,le=%s} %d\n", name, label, quote(formatFloat(bound)), h.counts[i])
+		}
+		w.printf("%s_bucket{operation=%s,le=\"+Inf\"} %d\n", name, label, h.count)
+		w.printf("%s_sum{operation=%s} %s\n", name, label, formatFloat(h.sum))
+		w.printf("%s_count{operation=%s} %d\n", name, label, h.count)
+	}
+}
+
+func (m *Metrics) writeFallbacks(w *countingWriter) {
+	name := namespace + "_fallbacks_total"
+	w.printf("# HELP %s Forecast fields taken from a coarser resolution than preferred.\n# TYPE %s counter\n", name, name)
+
+	keys := make([]fallbackKey, 0, len(m.fallbacks))
+	for k := range m.fallbacks {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].field != keys[j].field {
+			return keys[i].field < keys[j].field
+		}
+		if keys[i].from != keys[j].from {
+			return keys[i].from < keys[j].from
+		}
+		return keys[i].to < keys[j].to
+	})
+
+	for _, k := range keys {
+		w.printf("%s{field=%s,from=%s,to=%s} %d\n", name, quote(k.field), quote(string(k.from)), quote(string(k.to)), m.fallbacks[k])
+	}
+}
+
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) printf(format string, args ...any) {
+	if c.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(c.w, format, args...)
+	c.n += int64(n)
+	c.err = err
+}
@@ -0,0 +1,51 @@
+package promhooks
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	omp "github.com/saktibimantara/open-meteo-parser"
+)
+
+func TestMetrics_WriteTo(t *testing.T) {
+	m := NewWithBuckets([]float64{0.1, 1})
+
+	now := time.Unix(0, 0)
+	m.now = func() time.Time { return now }
+
+	done := m.Start(omp.OperationFetchForecast)
+	now = now.Add(500 * time.Millisecond)
+	done(nil)
+
+	done = m.Start(omp.OperationFetchForecast)
+	now = now.Add(2 * time.Second)
+	done(errors.New("boom"))
+
+	m.Fallback("main.temp", omp.ResolutionMinutely15, omp.ResolutionHourly)
+	m.Fallback("main.temp", omp.ResolutionMinutely15, omp.ResolutionHourly)
+
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`open_meteo_parser_operations_total{operation="fetch_forecast",result="error"} 1`,
+		`open_meteo_parser_operations_total{operation="fetch_forecast",result="success"} 1`,
+		`open_meteo_parser_operation_duration_seconds_bucket{operation="fetch_forecast",le="0.1"} 0`,
+		`open_meteo_parser_operation_duration_seconds_bucket{operation="fetch_forecast",le="1"} 1`,
+		`open_meteo_parser_operation_duration_seconds_bucket{operation="fetch_forecast",le="+Inf"} 2`,
+		`open_meteo_parser_operation_duration_seconds_sum{operation="fetch_forecast"} 2.5`,
+		`open_meteo_parser_operation_duration_seconds_count{operation="fetch_forecast"} 2`,
+		`open_meteo_parser_fallbacks_total{field="main.temp",from="minutely_15",to="hourly"} 2`,
+	}
+
+	got := b.String()
+	for _, line := range want {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, got)
+		}
+	}
+}
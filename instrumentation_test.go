@@ -0,0 +1,69 @@
+package open_meteo_parser
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingInstrumentation struct {
+	mu        sync.Mutex
+	ops       []Operation
+	fallbacks map[string]Resolution
+}
+
+func (r *recordingInstrumentation) Start(op Operation) func(err error) {
+	return func(error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.ops = append(r.ops, op)
+	}
+}
+
+func (r *recordingInstrumentation) Fallback(field string, from, to Resolution) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fallbacks == nil {
+		r.fallbacks = map[string]Resolution{}
+	}
+	r.fallbacks[field] = to
+}
+
+func TestParser_SetInstrumentation(t *testing.T) {
+	instr := &recordingInstrumentation{}
+	p := NewParser("xxx", "https://ddd.cloudfront.net").SetInstrumentation(instr)
+	p.now = fakeNow
+	p.om = &fakeOpenMeteo{}
+
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.GetOpenWeatherAQI(-8.68, 115.2, startTime); err != nil {
+		t.Fatal(err)
+	}
+
+	wantOps := []Operation{
+		OperationFetchForecast, OperationLookup, OperationParse,
+		OperationFetchAQI, OperationLookup, OperationAQI,
+	}
+	if !reflect.DeepEqual(instr.ops, wantOps) {
+		t.Errorf("operations = %v, want %v", instr.ops, wantOps)
+	}
+
+	// the fake response has no minutely15 series
+	for _, field := range []string{"dt", "main.temp", "weather", "wind.speed", "wind.deg"} {
+		if got := instr.fallbacks[field]; got != ResolutionHourly {
+			t.Errorf("fallback for %s = %q, want hourly", field, got)
+		}
+	}
+
+	for _, field := range []string{"main.pressure", "main.temp_max"} {
+		if got, ok := instr.fallbacks[field]; ok {
+			t.Errorf("unexpected fallback for %s to %q", field, got)
+		}
+	}
+}
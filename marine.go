@@ -0,0 +1,304 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const marinePath = "/v1/marine"
+
+var marineHourlyParams = []string{
+	"wave_height",
+	"wave_direction",
+	"wave_period",
+	"wind_wave_height",
+	"wind_wave_direction",
+	"wind_wave_period",
+	"swell_wave_height",
+	"swell_wave_direction",
+	"swell_wave_period",
+	"sea_surface_temperature",
+	"sea_level_height_msl",
+}
+
+// Tide types.
+const (
+	TideHigh = "high"
+	TideLow  = "low"
+)
+
+type (
+	// MarineForecast is the sea state at one time from Open-Meteo's marine
+	// API. SeaLevel is the height in metres above mean sea level, tides
+	// included, and SeaSurfaceTemperature is in °C. Values the API has no
+	// sample for, such as waves in sheltered waters, are nil.
+	MarineForecast struct {
+		Dt                    int      `json:"dt"`
+		DtTxt                 string   `json:"dt_txt"`
+		Wave                  Waves    `json:"wave"`
+		WindWave              Waves    `json:"wind_wave"`
+		Swell                 Waves    `json:"swell"`
+		SeaSurfaceTemperature *float64 `json:"sea_surface_temperature,omitempty"`
+		SeaLevel              *float64 `json:"sea_level,omitempty"`
+	}
+
+	// Waves holds a wave height in metres, the direction the waves come
+	// from in degrees and their period in seconds.
+	Waves struct {
+		Height    *float64 `json:"height,omitempty"`
+		Direction *int     `json:"direction,omitempty"`
+		Period    *float64 `json:"period,omitempty"`
+	}
+
+	// Tide is a high or low of the hourly sea level series.
+	Tide struct {
+		Dt     int     `json:"dt"`
+		Type   string  `json:"type"`
+		Height float64 `json:"height"`
+	}
+
+	// ResponseMarineForecast is an hourly marine series. Timezone is the
+	// location's offset from UTC in seconds.
+	ResponseMarineForecast struct {
+		Coord    Coord            `json:"coord"`
+		Timezone int              `json:"timezone"`
+		Cnt      int              `json:"cnt"`
+		List     []MarineForecast `json:"list"`
+		Tides    []Tide           `json:"tides"`
+	}
+)
+
+// marineResponse is the marine API's response, requested with unix times.
+type marineResponse struct {
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	Timezone         string  `json:"timezone"`
+	UTCOffsetSeconds int     `json:"utc_offset_seconds"`
+	Hourly           *struct {
+		Time               []int64    `json:"time"`
+		WaveHeight         []*float64 `json:"wave_height"`
+		WaveDirection      []*float64 `json:"wave_direction"`
+		WavePeriod         []*float64 `json:"wave_period"`
+		WindWaveHeight     []*float64 `json:"wind_wave_height"`
+		WindWaveDirection  []*float64 `json:"wind_wave_direction"`
+		WindWavePeriod     []*float64 `json:"wind_wave_period"`
+		SwellWaveHeight    []*float64 `json:"swell_wave_height"`
+		SwellWaveDirection []*float64 `json:"swell_wave_direction"`
+		SwellWavePeriod    []*float64 `json:"swell_wave_period"`
+		SeaSurfaceTemp     []*float64 `json:"sea_surface_temperature"`
+		SeaLevelHeightMSL  []*float64 `json:"sea_level_height_msl"`
+	} `json:"hourly"`
+}
+
+// GetMarineForecast returns the sea state nearest startTime from
+// Open-Meteo's marine API, which serves seven days ahead. Locations inland
+// fail with the API's error.
+func (p Parser) GetMarineForecast(latitude, longitude float64, startTime time.Time) (*MarineForecast, error) {
+	resp, err := p.fetchMarine(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	i, ok := resp.nearest(startTime)
+	if !ok {
+		return nil, ErrNoForecastAtTime
+	}
+
+	forecast := resp.forecastAt(i)
+	return &forecast, nil
+}
+
+// GetMarineForecastSeries returns hours hourly sea states from startTime's
+// hour, with the tide highs and lows among them, for tide charts. The list
+// stops early at the end of the marine forecast.
+func (p Parser) GetMarineForecastSeries(latitude, longitude float64, startTime time.Time, hours int) (*ResponseMarineForecast, error) {
+	if hours < 1 {
+		return nil, fmt.Errorf("invalid marine series: hours %d", hours)
+	}
+
+	resp, err := p.fetchMarine(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	i, ok := resp.nearest(startTime.Truncate(time.Hour))
+	if !ok {
+		return nil, ErrNoForecastAtTime
+	}
+
+	series := &ResponseMarineForecast{
+		Coord: Coord{Lat: resp.Latitude, Lon: resp.Longitude},
+		List:  make([]MarineForecast, 0, hours),
+	}
+
+	for ; i < len(resp.Hourly.Time) && len(series.List) < hours; i++ {
+		series.List = append(series.List, resp.forecastAt(i))
+	}
+
+	series.Cnt = len(series.List)
+	series.Tides = tides(series.List)
+
+	loc := responseLocation(resp.Timezone, resp.UTCOffsetSeconds)
+	first := time.Unix(int64(series.List[0].Dt), 0)
+	series.Timezone = utcOffset(&first, loc)
+
+	return series, nil
+}
+
+func (p Parser) fetchMarine(lat, lon float64) (*marineResponse, error) {
+	if err := validateCoordinates(lat, lon); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(lat, 'f', -1, 64))
+	query.Set("longitude", strconv.FormatFloat(lon, 'f', -1, 64))
+	query.Set("hourly", strings.Join(marineHourlyParams, ","))
+	query.Set("timeformat", "unixtime")
+	if p.timezone != "" {
+		query.Set("timezone", p.timezone)
+	}
+
+	key := p.apiURL("marine", marinePath) + "?" + query.Encode()
+	resp, err := p.flights.do(key, func() (any, error) {
+		var data []byte
+		done := p.start(OperationFetchMarine)
+		err := p.call(requestWeight(query), func() (err error) {
+			data, err = fetch(p.callApi, key)
+			return err
+		})
+		done(err)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp marineResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+		}
+
+		return &resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*marineResponse), nil
+}
+
+// nearest returns the hourly sample nearest t, if one is within an hour.
+func (r *marineResponse) nearest(t time.Time) (int, bool) {
+	if r.Hourly == nil {
+		return 0, false
+	}
+
+	return nearestHour(r.Hourly.Time, t)
+}
+
+// nearestHour returns the index of the unix time nearest t, if one is
+// within an hour.
+func nearestHour(times []int64, t time.Time) (int, bool) {
+	best, bestDiff := -1, int64(math.MaxInt64)
+	for i, ts := range times {
+		diff := ts - t.Unix()
+		if diff < 0 {
+			diff = -diff
+		}
+
+		if diff < bestDiff {
+			best, bestDiff = i, diff
+		}
+	}
+
+	if best < 0 || bestDiff >= int64(time.Hour/time.Second) {
+		return 0, false
+	}
+
+	return best, true
+}
+
+func (r *marineResponse) forecastAt(i int) MarineForecast {
+	h := r.Hourly
+	dt := time.Unix(h.Time[i], 0)
+
+	return MarineForecast{
+		Dt:    int(dt.Unix()),
+		DtTxt: dt.UTC().Format(DtTxtLayout),
+		Wave: Waves{
+			Height:    sampleAt(h.WaveHeight, i),
+			Direction: degreesAt(h.WaveDirection, i),
+			Period:    sampleAt(h.WavePeriod, i),
+		},
+		WindWave: Waves{
+			Height:    sampleAt(h.WindWaveHeight, i),
+			Direction: degreesAt(h.WindWaveDirection, i),
+			Period:    sampleAt(h.WindWavePeriod, i),
+		},
+		Swell: Waves{
+			Height:    sampleAt(h.SwellWaveHeight, i),
+			Direction: degreesAt(h.SwellWaveDirection, i),
+			Period:    sampleAt(h.SwellWavePeriod, i),
+		},
+		SeaSurfaceTemperature: sampleAt(h.SeaSurfaceTemp, i),
+		SeaLevel:              sampleAt(h.SeaLevelHeightMSL, i),
+	}
+}
+
+// sampleAt returns values[i], or nil when the series is short or the sample
+// is null.
+func sampleAt(values []*float64, i int) *float64 {
+	if i >= len(values) {
+		return nil
+	}
+
+	return values[i]
+}
+
+// degreesAt returns the direction values[i] in whole degrees, or nil.
+func degreesAt(values []*float64, i int) *int {
+	v := sampleAt(values, i)
+	if v == nil {
+		return nil
+	}
+
+	deg := int(*v)
+	return &deg
+}
+
+// tides returns the turning points of the sea level series, skipping hours
+// without a sea level. A plateau counts once, at its first hour.
+func tides(list []MarineForecast) []Tide {
+	known := make([]MarineForecast, 0, len(list))
+	for _, f := range list {
+		if f.SeaLevel != nil {
+			known = append(known, f)
+		}
+	}
+	list = known
+
+	out := []Tide{}
+
+	for i := 1; i+1 < len(list); i++ {
+		prev, cur := *list[i-1].SeaLevel, *list[i].SeaLevel
+
+		j := i + 1
+		for j < len(list)-1 && *list[j].SeaLevel == cur {
+			j++
+		}
+		next := *list[j].SeaLevel
+
+		switch {
+		case cur > prev && cur > next:
+			out = append(out, Tide{Dt: list[i].Dt, Type: TideHigh, Height: cur})
+		case cur < prev && cur < next:
+			out = append(out, Tide{Dt: list[i].Dt, Type: TideLow, Height: cur})
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,168 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeMarineJSON = `{
+	"latitude": -8.75,
+	"longitude": 115.125,
+	"timezone": "Asia/Makassar",
+	"utc_offset_seconds": 28800,
+	"hourly": {
+		"time": [1714521600, 1714525200, 1714528800, 1714532400, 1714536000, 1714539600, 1714543200, 1714546800],
+		"wave_height": [1.4, 1.5, 1.6, 1.8, 1.9, 1.9, 1.7, 1.6],
+		"wave_direction": [200, 202, 205, 210, 212, 211, 208, 205],
+		"wave_period": [11.2, 11.5, 11.9, 12.3, 12.6, 12.4, 12.1, 11.8],
+		"wind_wave_height": [0.3, 0.3, 0.4, 0.4, 0.5, 0.5, 0.4, 0.3],
+		"wind_wave_direction": [120, 125, 130, 130, 135, 135, 130, 125],
+		"wind_wave_period": [3.1, 3.2, 3.4, 3.5, 3.6, 3.6, 3.4, 3.2],
+		"swell_wave_height": [1.3, 1.4, 1.5, 1.7, 1.8, 1.8, 1.6, 1.5],
+		"swell_wave_direction": [205, 206, 208, 212, 214, 213, 210, 207],
+		"swell_wave_period": [13.5, 13.8, 14.1, 14.4, 14.6, 14.5, 14.2, 13.9],
+		"sea_surface_temperature": [28.4, 28.4, 28.5, 28.6, 28.7, 28.7, 28.6, 28.5],
+		"sea_level_height_msl": [-0.6, -0.2, 0.4, 0.9, 0.9, 0.5, -0.1, 0.2]
+	}
+}`
+
+func TestParser_GetMarineForecast(t *testing.T) {
+	p, caller := newFakeParser(fakeMarineJSON)
+
+	start := time.Date(2024, 5, 1, 2, 20, 0, 0, time.UTC)
+	forecast, err := p.GetMarineForecast(-8.72, 115.17, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := MarineForecast{
+		Dt:                    1714528800,
+		DtTxt:                 "2024-05-01 02:00:00",
+		Wave:                  waves(1.6, 205, 11.9),
+		WindWave:              waves(0.4, 130, 3.4),
+		Swell:                 waves(1.5, 208, 14.1),
+		SeaSurfaceTemperature: ptr(28.5),
+		SeaLevel:              ptr(0.4),
+	}
+	if !reflect.DeepEqual(*forecast, want) {
+		t.Errorf("got %+v, want %+v", *forecast, want)
+	}
+
+	calls := caller.calls()
+	if len(calls) != 1 || !strings.HasPrefix(calls[0], "https://marine-api.open-meteo.com/v1/marine?") {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+
+	u, _ := url.Parse(calls[0])
+	if q := u.Query(); q.Get("timeformat") != "unixtime" || q.Get("timezone") != TimezoneAuto || !strings.Contains(q.Get("hourly"), "swell_wave_period") {
+		t.Errorf("unexpected query %v", q)
+	}
+
+	if _, err := p.GetMarineForecast(-8.72, 115.17, start.Add(24*time.Hour)); !errors.Is(err, ErrNoForecastAtTime) {
+		t.Errorf("err = %v, want ErrNoForecastAtTime", err)
+	}
+}
+
+func TestParser_GetMarineForecastSeries(t *testing.T) {
+	p, _ := newFakeParser(fakeMarineJSON)
+
+	series, err := p.GetMarineForecastSeries(-8.72, 115.17, time.Date(2024, 5, 1, 0, 30, 0, 0, time.UTC), 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if series.Cnt != 8 || len(series.List) != 8 || series.Timezone != 28800 {
+		t.Fatalf("got %d entries, timezone %d", series.Cnt, series.Timezone)
+	}
+
+	want := []Tide{
+		{Dt: 1714532400, Type: TideHigh, Height: 0.9},
+		{Dt: 1714543200, Type: TideLow, Height: -0.1},
+	}
+	if !reflect.DeepEqual(series.Tides, want) {
+		t.Errorf("tides = %+v, want %+v", series.Tides, want)
+	}
+
+	short, err := p.GetMarineForecastSeries(-8.72, 115.17, time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if short.Cnt != 2 || short.List[0].Dt != 1714532400 || len(short.Tides) != 0 {
+		t.Errorf("unexpected short series %+v", short)
+	}
+
+	if _, err := p.GetMarineForecastSeries(-8.72, 115.17, time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC), 0); err == nil {
+		t.Error("expected error for zero hours")
+	}
+}
+
+func TestParser_GetMarineForecastNulls(t *testing.T) {
+	// sheltered waters have swell but no wind waves, and the sea level
+	// has a gap
+	body := strings.NewReplacer(
+		`"wind_wave_height": [0.3, 0.3, 0.4,`, `"wind_wave_height": [0.3, 0.3, null,`,
+		`"wind_wave_period": [3.1, 3.2, 3.4,`, `"wind_wave_period": [3.1, 3.2, null,`,
+		`"sea_level_height_msl": [-0.6, -0.2, 0.4,`, `"sea_level_height_msl": [-0.6, -0.2, null,`,
+	).Replace(fakeMarineJSON)
+
+	p, _ := newFakeParser(body)
+
+	start := time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	forecast, err := p.GetMarineForecast(-8.72, 115.17, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if forecast.WindWave.Height != nil || forecast.WindWave.Period != nil || forecast.SeaLevel != nil {
+		t.Errorf("null samples read as values: %+v", *forecast)
+	}
+
+	if !reflect.DeepEqual(forecast.Swell, waves(1.5, 208, 14.1)) {
+		t.Errorf("swell = %+v", forecast.Swell)
+	}
+
+	data, err := json.Marshal(forecast)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"wind_wave":{"direction":130}`) || strings.Contains(string(data), `"sea_level"`) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+
+	// the tides skip the hour without a sea level
+	series, err := p.GetMarineForecastSeries(-8.72, 115.17, start.Add(-2*time.Hour), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Tide{
+		{Dt: 1714532400, Type: TideHigh, Height: 0.9},
+		{Dt: 1714543200, Type: TideLow, Height: -0.1},
+	}
+	if !reflect.DeepEqual(series.Tides, want) {
+		t.Errorf("tides = %+v, want %+v", series.Tides, want)
+	}
+}
+
+func TestParser_GetMarineForecastDecodeError(t *testing.T) {
+	p, _ := newFakeParser(`{"hourly": {"wave_height": "high"}}`)
+
+	if _, err := p.GetMarineForecast(-8.72, 115.17, fakeNow()); !errors.Is(err, ErrDecodeResponse) {
+		t.Errorf("err = %v, want ErrDecodeResponse", err)
+	}
+}
+
+func waves(height float64, direction int, period float64) Waves {
+	return Waves{Height: ptr(height), Direction: ptr(direction), Period: ptr(period)}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
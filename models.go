@@ -0,0 +1,287 @@
+package open_meteo_parser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Model is an Open-Meteo weather model. The seamless models combine a
+// global model with its regional ones where they cover the location.
+type Model string
+
+const (
+	ModelBestMatch   Model = "best_match"
+	ModelICON        Model = "icon_seamless"
+	ModelGFS         Model = "gfs_seamless"
+	ModelECMWF       Model = "ecmwf_ifs025"
+	ModelJMA         Model = "jma_seamless"
+	ModelGEM         Model = "gem_seamless"
+	ModelMeteoFrance Model = "meteofrance_seamless"
+	ModelUKMO        Model = "ukmo_seamless"
+	ModelCMA         Model = "cma_grapes_global"
+	ModelBOM         Model = "bom_access_global"
+)
+
+// ModelWeights weights the models of a blended forecast. Weights are
+// relative and need not sum to one.
+type ModelWeights map[Model]float64
+
+// ModelForecast is one model's forecast, or the error fetching it.
+type ModelForecast struct {
+	Model    Model
+	Forecast *Forecast
+	Err      error
+}
+
+// blendFloats and blendInts are the forecast values a blend averages.
+var (
+	blendFloats = map[string]func(*Forecast) *float64{
+		"main.temp":               func(f *Forecast) *float64 { return &f.Main.Temp },
+		"main.feels_like":         func(f *Forecast) *float64 { return &f.Main.FeelsLike },
+		"main.temp_max":           func(f *Forecast) *float64 { return &f.Main.TempMax },
+		"main.temp_min":           func(f *Forecast) *float64 { return &f.Main.TempMin },
+		"main.pressure_change":    func(f *Forecast) *float64 { return ensure(&f.Main.PressureChange) },
+		"wind.speed":              func(f *Forecast) *float64 { return &f.Wind.Speed },
+		"wind.gust":               func(f *Forecast) *float64 { return &f.Wind.Gust },
+		"rain.3h":                 func(f *Forecast) *float64 { return &f.Rain.ThreeH },
+		"pop":                     func(f *Forecast) *float64 { return &f.Pop },
+		"uvi":                     func(f *Forecast) *float64 { return ensure(&f.Uvi) },
+		"uv.max":                  func(f *Forecast) *float64 { return &ensure(&f.UV).Max },
+		"solar.radiation":         func(f *Forecast) *float64 { return &ensure(&f.Solar).Radiation },
+		"solar.radiation_sum":     func(f *Forecast) *float64 { return &ensure(&f.Solar).RadiationSum },
+		"solar.sunshine_duration": func(f *Forecast) *float64 { return &ensure(&f.Solar).SunshineDuration },
+	}
+
+	blendInts = map[string]func(*Forecast) *int{
+		"main.pressure":   func(f *Forecast) *int { return &f.Main.Pressure },
+		"main.sea_level":  func(f *Forecast) *int { return &f.Main.SeaLevel },
+		"main.grnd_level": func(f *Forecast) *int { return &f.Main.GrndLevel },
+		"main.humidity":   func(f *Forecast) *int { return &f.Main.Humidity },
+	}
+)
+
+// ensure returns the optional value *p, allocating it for a blend that has
+// values its heaviest forecast lacks.
+func ensure[T any](p **T) *T {
+	if *p == nil {
+		*p = new(T)
+	}
+	return *p
+}
+
+// SetModel selects the weather model forecasts, forecast lists and daily
+// forecasts are read from. The default, ModelBestMatch, lets Open-Meteo pick
+// the best models for the location. Historical forecasts from the archive
+// are not affected.
+func (p *Parser) SetModel(model Model) *Parser {
+	p.model = model
+	return p
+}
+
+// GetOpenWeatherForecastModels returns each model's forecast at startTime
+// side by side, in the order of models. The models are fetched concurrently,
+// one request each, and each result carries either a forecast or its own
+// error.
+func (p Parser) GetOpenWeatherForecastModels(latitude, longitude float64, startTime time.Time, models ...Model) []ModelForecast {
+	results := make([]ModelForecast, len(models))
+
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func(i int, model Model) {
+			defer wg.Done()
+
+			q := p
+			q.model = model
+			forecast, err := q.GetOpenWeatherForecast(latitude, longitude, startTime)
+			results[i] = ModelForecast{Model: model, Forecast: forecast, Err: err}
+		}(i, model)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// GetOpenWeatherForecastBlend returns the weighted mean of the models'
+// forecasts at startTime. Numeric values are averaged over the models that
+// have them, and the wind direction as a vector. The weather condition and
+// the timestamps are the most heavily weighted model's. Any model failing
+// fails the blend.
+func (p Parser) GetOpenWeatherForecastBlend(latitude, longitude float64, startTime time.Time, weights ModelWeights) (*Forecast, error) {
+	if len(weights) == 0 {
+		return nil, errors.New("invalid model blend: no models")
+	}
+
+	models := make([]Model, 0, len(weights))
+	for model, weight := range weights {
+		if weight <= 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("invalid model blend: weight %v for %s", weight, model)
+		}
+		models = append(models, model)
+	}
+
+	// heaviest first, so the blend starts from its forecast
+	sort.Slice(models, func(i, j int) bool {
+		if weights[models[i]] != weights[models[j]] {
+			return weights[models[i]] > weights[models[j]]
+		}
+		return models[i] < models[j]
+	})
+
+	results := p.GetOpenWeatherForecastModels(latitude, longitude, startTime, models...)
+
+	forecasts := make([]*Forecast, len(results))
+	ws := make([]float64, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			return nil, fmt.Errorf("model %s: %w", result.Model, result.Err)
+		}
+
+		forecasts[i] = result.Forecast
+		ws[i] = weights[result.Model]
+	}
+
+	return blendForecasts(forecasts, ws), nil
+}
+
+// blendForecasts averages forecasts into the first one, weighting each by
+// ws, and returns it; forecasts[0] is modified in place. Each value's source
+// is the coarsest it was averaged from.
+func blendForecasts(forecasts []*Forecast, ws []float64) *Forecast {
+	blend := forecasts[0]
+
+	mean := func(field string, value func(*Forecast) float64) (float64, bool) {
+		var sum, total float64
+		var res Resolution
+
+		for i, f := range forecasts {
+			r, ok := f.sources[field]
+			if !ok {
+				continue
+			}
+
+			if total == 0 {
+				res = r
+			}
+			res = coarser(res, r)
+
+			sum += ws[i] * value(f)
+			total += ws[i]
+		}
+
+		if total == 0 {
+			return 0, false
+		}
+
+		blend.sources[field] = res
+		return sum / total, true
+	}
+
+	// the direction is a vector mean, taken before the blend's speed is
+	// overwritten; the speed is a scalar mean, so models agreeing on the
+	// speed but not the direction keep it
+	var speeds, degs, dirWeights []float64
+	var dirRes Resolution
+	for i, f := range forecasts {
+		speed, hasSpeed := f.sources["wind.speed"]
+		deg, hasDeg := f.sources["wind.deg"]
+		if !hasSpeed || !hasDeg {
+			continue
+		}
+
+		if len(speeds) == 0 {
+			dirRes = deg
+		}
+		dirRes = coarser(dirRes, coarser(speed, deg))
+
+		speeds = append(speeds, f.Wind.Speed)
+		degs = append(degs, float64(f.Wind.Deg))
+		dirWeights = append(dirWeights, ws[i])
+	}
+
+	if len(speeds) > 0 {
+		_, deg := weightedMeanWind(speeds, degs, dirWeights)
+		blend.Wind.Deg = int(math.Round(deg)) % 360
+		blend.sources["wind.deg"] = dirRes
+	}
+
+	for field, ptr := range blendFloats {
+		if v, ok := mean(field, func(f *Forecast) float64 { return *ptr(f) }); ok {
+			*ptr(blend) = roundTo(v, 2)
+		}
+	}
+
+	for field, ptr := range blendInts {
+		if v, ok := mean(field, func(f *Forecast) float64 { return float64(*ptr(f)) }); ok {
+			*ptr(blend) = int(math.Round(v))
+		}
+	}
+
+	if _, ok := blend.sources["main.pressure_change"]; ok {
+		blend.Main.PressureTendency = PressureTendency(*blend.Main.PressureChange)
+	}
+
+	// a value averaged from other models replaces the heaviest one's
+	// approximation
+	for field := range blend.approximated {
+		if _, ok := blend.sources[field]; ok {
+			delete(blend.approximated, field)
+		}
+	}
+
+	blend.Wind.describe(blend.sources)
+	blend.derive(blend.sources)
+	blend.describeUV()
+	blendProvenance(blend, forecasts)
+
+	return blend
+}
+
+// blendProvenance rebuilds the blend's provenance, when enabled, from its
+// sources and approximations. A field takes the sample of the first forecast
+// whose value came from the same resolution, and a value derived or
+// approximated from the blend that of any field sampled at its resolution.
+func blendProvenance(blend *Forecast, forecasts []*Forecast) {
+	if blend.Provenance == nil {
+		return
+	}
+
+	samples := map[Resolution]FieldProvenance{}
+	for _, f := range forecasts {
+		for _, fp := range f.Provenance {
+			if _, ok := samples[fp.Resolution]; !ok {
+				fp.Approximated = false
+				samples[fp.Resolution] = fp
+			}
+		}
+	}
+
+	out := make(map[string]FieldProvenance, len(blend.sources))
+	for field, res := range blend.sources {
+		fp, ok := samples[res]
+		for _, f := range forecasts {
+			if own, found := f.Provenance[field]; found && own.Resolution == res && !own.Approximated {
+				fp, ok = own, true
+				break
+			}
+		}
+
+		if ok {
+			out[field] = fp
+		}
+	}
+
+	for field, res := range blend.approximated {
+		if fp, ok := samples[res]; ok {
+			fp.Approximated = true
+			out[field] = fp
+		}
+	}
+
+	blend.Provenance = out
+}
@@ -0,0 +1,175 @@
+package open_meteo_parser
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	go_http "github.com/saktibimantara/go-http"
+)
+
+const fakeICONJSON = `{
+	"latitude": -8.68,
+	"longitude": 115.2,
+	"timezone": "GMT",
+	"hourly": {
+		"time": ["2024-05-01T00:00", "2024-05-01T01:00", "2024-05-01T02:00"],
+		"temperature_2m": [26.0, 27.0, 28.0],
+		"wind_speed_10m": [10, 10, 10],
+		"wind_direction_10m": [350, 350, 350],
+		"pressure_msl": [1011.0, 1011.0, 1011.0],
+		"weather_code": [3, 3, 3]
+	}
+}`
+
+const fakeGFSJSON = `{
+	"latitude": -8.68,
+	"longitude": 115.2,
+	"timezone": "GMT",
+	"hourly": {
+		"time": ["2024-05-01T00:00", "2024-05-01T01:00", "2024-05-01T02:00"],
+		"temperature_2m": [29.0, 30.0, 31.0],
+		"wind_speed_10m": [20, 20, 20],
+		"wind_direction_10m": [10, 10, 10],
+		"pressure_msl": [1014.0, 1014.0, 1014.0],
+		"weather_code": [61, 61, 61]
+	}
+}`
+
+// newModelsParser returns a parser serving the ICON and GFS responses by
+// the requested model, and the best match response otherwise.
+func newModelsParser() (*Parser, *fakeCaller) {
+	p, caller := newFakeParser(fakeForecastJSON)
+	caller.respond = func(rawURL string) (*go_http.Response, error) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+
+		switch Model(u.Query().Get("models")) {
+		case ModelICON:
+			return &go_http.Response{Code: 200, Data: []byte(fakeICONJSON)}, nil
+		case ModelGFS:
+			return &go_http.Response{Code: 200, Data: []byte(fakeGFSJSON)}, nil
+		case "":
+			return &go_http.Response{Code: 200, Data: []byte(fakeForecastJSON)}, nil
+		}
+
+		return nil, &UpstreamError{StatusCode: 400, Reason: "Invalid model"}
+	}
+
+	return p, caller
+}
+
+func TestParser_SetModel(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		model Model
+		want  string
+		temp  float64
+	}{
+		{"", "", 27.5},
+		{ModelBestMatch, "", 27.5},
+		{ModelGFS, string(ModelGFS), 30},
+	} {
+		p, caller := newModelsParser()
+		p.SetModel(tt.model)
+
+		forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		u, _ := url.Parse(caller.calls()[0])
+		if got := u.Query().Get("models"); got != tt.want || forecast.Main.Temp != tt.temp {
+			t.Errorf("SetModel(%q): models %q, temp %v", tt.model, got, forecast.Main.Temp)
+		}
+	}
+}
+
+func TestParser_GetOpenWeatherForecastModels(t *testing.T) {
+	p, caller := newModelsParser()
+
+	results := p.GetOpenWeatherForecastModels(-8.68, 115.2, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC), ModelICON, ModelGFS, "unknown")
+	if len(results) != 3 {
+		t.Fatalf("got %d results", len(results))
+	}
+
+	if got := results[0]; got.Model != ModelICON || got.Err != nil || got.Forecast.Main.Temp != 27 {
+		t.Errorf("ICON result %+v", got)
+	}
+
+	if got := results[1]; got.Model != ModelGFS || got.Err != nil || got.Forecast.Main.Temp != 30 || got.Forecast.Weather[0].Main != "Rain" {
+		t.Errorf("GFS result %+v", got)
+	}
+
+	if got := results[2]; got.Err == nil || got.Forecast != nil {
+		t.Errorf("unknown model result %+v", got)
+	}
+
+	if calls := caller.calls(); len(calls) != 3 {
+		t.Errorf("got %d calls, want 3", len(calls))
+	}
+}
+
+func TestParser_GetOpenWeatherForecastBlend(t *testing.T) {
+	p, _ := newModelsParser()
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	blend, err := p.GetOpenWeatherForecastBlend(-8.68, 115.2, startTime, ModelWeights{ModelICON: 3, ModelGFS: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if blend.Main.Temp != 27.75 || blend.Main.Pressure != 1012 || blend.Wind.Speed != 12.5 {
+		t.Errorf("temp %v, pressure %d, wind speed %v", blend.Main.Temp, blend.Main.Pressure, blend.Wind.Speed)
+	}
+
+	// 30 km/h weighted from 350° and 20 km/h from 10° average to just west
+	// of north
+	if blend.Wind.Deg != 358 || blend.Wind.Direction != "N" || blend.Wind.Beaufort != 3 {
+		t.Errorf("wind %+v", blend.Wind)
+	}
+
+	// the weather is the heavier model's
+	if blend.Weather[0].Main != "Clouds" || blend.Dt != 1714525200 {
+		t.Errorf("weather %+v at %d", blend.Weather[0], blend.Dt)
+	}
+
+	if blend.Derived.WindChill != WindChill(27.75, 12.5) {
+		t.Errorf("wind chill %v not derived from the blend", blend.Derived.WindChill)
+	}
+
+	// the provenance follows the blend's sources, including values derived
+	// from it
+	p.SetProvenance(true)
+	blend, err = p.GetOpenWeatherForecastBlend(-8.68, 115.2, startTime, ModelWeights{ModelICON: 3, ModelGFS: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(blend.Provenance) != len(blend.sources)+len(blend.approximated) {
+		t.Errorf("provenance %v for sources %v", blend.Provenance, blend.sources)
+	}
+
+	want := FieldProvenance{Resolution: ResolutionHourly, Dt: 1714525200}
+	if got := blend.Provenance["derived.wind_chill"]; got != want {
+		t.Errorf("wind chill provenance %+v, want %+v", got, want)
+	}
+
+	want.Approximated = true
+	if got := blend.Provenance["main.feels_like"]; got != want {
+		t.Errorf("feels_like provenance %+v, want %+v", got, want)
+	}
+
+	for _, weights := range []ModelWeights{nil, {ModelICON: 0}, {ModelICON: 1, ModelGFS: -1}} {
+		if _, err := p.GetOpenWeatherForecastBlend(-8.68, 115.2, startTime, weights); err == nil {
+			t.Errorf("expected error for weights %v", weights)
+		}
+	}
+
+	if _, err := p.GetOpenWeatherForecastBlend(-8.68, 115.2, startTime, ModelWeights{ModelICON: 1, "unknown": 1}); err == nil {
+		t.Error("expected error for a failing model")
+	}
+}
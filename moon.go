@@ -0,0 +1,173 @@
+package open_meteo_parser
+
+import (
+	"math"
+	"time"
+)
+
+// Open-Meteo has no lunar data, so moonrise, moonset and phase are computed
+// with the low-precision formulas used by SunCalc (Astronomy Answers),
+// accurate to a few minutes.
+
+const (
+	julian1970 = 2440588.0
+	julian2000 = 2451545.0
+
+	earthObliquity = math.Pi / 180 * 23.4397
+
+	// moonHorizon is the altitude of the moon's centre at rise and set,
+	// accounting for parallax, refraction and its radius.
+	moonHorizon = math.Pi / 180 * 0.133
+
+	sunDistanceKm = 149598000.0
+)
+
+type celestialCoords struct {
+	ra, dec, dist float64
+}
+
+func daysSinceJ2000(t time.Time) float64 {
+	return float64(t.UnixMilli())/float64(24*time.Hour/time.Millisecond) - 0.5 + julian1970 - julian2000
+}
+
+func rightAscension(l, b float64) float64 {
+	return math.Atan2(math.Sin(l)*math.Cos(earthObliquity)-math.Tan(b)*math.Sin(earthObliquity), math.Cos(l))
+}
+
+func declination(l, b float64) float64 {
+	return math.Asin(math.Sin(b)*math.Cos(earthObliquity) + math.Cos(b)*math.Sin(earthObliquity)*math.Sin(l))
+}
+
+func moonCoords(d float64) celestialCoords {
+	l := toRadians(218.316 + 13.176396*d)
+	m := toRadians(134.963 + 13.064993*d)
+	f := toRadians(93.272 + 13.229350*d)
+
+	lon := l + toRadians(6.289)*math.Sin(m)
+	lat := toRadians(5.128) * math.Sin(f)
+
+	return celestialCoords{
+		ra:   rightAscension(lon, lat),
+		dec:  declination(lon, lat),
+		dist: 385001 - 20905*math.Cos(m),
+	}
+}
+
+func sunCoords(d float64) celestialCoords {
+	m := toRadians(357.5291 + 0.98560028*d)
+	c := toRadians(1.9148*math.Sin(m) + 0.02*math.Sin(2*m) + 0.0003*math.Sin(3*m))
+	l := m + c + toRadians(102.9372) + math.Pi
+
+	return celestialCoords{
+		ra:  rightAscension(l, 0),
+		dec: declination(l, 0),
+	}
+}
+
+// moonAltitude returns the moon's apparent altitude in radians.
+func moonAltitude(t time.Time, lat, lon float64) float64 {
+	d := daysSinceJ2000(t)
+	c := moonCoords(d)
+
+	phi := toRadians(lat)
+	h := toRadians(280.16+360.9856235*d) + toRadians(lon) - c.ra
+	alt := math.Asin(math.Sin(phi)*math.Sin(c.dec) + math.Cos(phi)*math.Cos(c.dec)*math.Cos(h))
+
+	// atmospheric refraction
+	refr := math.Max(alt, 0)
+	return alt + 0.0002967/math.Tan(refr+0.00312536/(refr+0.08901179))
+}
+
+// moonTimes returns the moonrise and moonset in the 24 hours from start, or
+// zero times when the moon does not rise or set in that window.
+func moonTimes(start time.Time, lat, lon float64) (rise, set time.Time) {
+	at := func(hours float64) time.Time {
+		return start.Add(time.Duration(hours * float64(time.Hour)))
+	}
+	alt := func(hours float64) float64 {
+		return moonAltitude(at(hours), lat, lon) - moonHorizon
+	}
+
+	var riseH, setH float64
+	var hasRise, hasSet bool
+
+	// fit a parabola through each 2-hour window and look for its roots
+	h0 := alt(0)
+	for i := 1.0; i <= 24; i += 2 {
+		h1 := alt(i)
+		h2 := alt(i + 1)
+
+		a := (h0+h2)/2 - h1
+		b := (h2 - h0) / 2
+		xe := -b / (2 * a)
+		ye := (a*xe+b)*xe + h1
+		disc := b*b - 4*a*h1
+
+		roots := 0
+		var x1, x2 float64
+		if disc >= 0 {
+			dx := math.Sqrt(disc) / (math.Abs(a) * 2)
+			x1, x2 = xe-dx, xe+dx
+			if math.Abs(x1) <= 1 {
+				roots++
+			}
+			if math.Abs(x2) <= 1 {
+				roots++
+			}
+			if x1 < -1 {
+				x1 = x2
+			}
+		}
+
+		switch roots {
+		case 1:
+			if h0 < 0 {
+				riseH, hasRise = i+x1, true
+			} else {
+				setH, hasSet = i+x1, true
+			}
+		case 2:
+			if ye < 0 {
+				riseH, setH = i+x2, i+x1
+			} else {
+				riseH, setH = i+x1, i+x2
+			}
+			hasRise, hasSet = true, true
+		}
+
+		if hasRise && hasSet {
+			break
+		}
+
+		h0 = h2
+	}
+
+	if hasRise {
+		rise = at(riseH)
+	}
+
+	if hasSet {
+		set = at(setH)
+	}
+
+	return rise, set
+}
+
+// moonPhase returns the phase in OpenWeather's convention: 0 and 1 are new
+// moon, 0.25 first quarter, 0.5 full moon and 0.75 last quarter.
+func moonPhase(t time.Time) float64 {
+	d := daysSinceJ2000(t)
+	s := sunCoords(d)
+	m := moonCoords(d)
+
+	phi := math.Acos(math.Sin(s.dec)*math.Sin(m.dec) + math.Cos(s.dec)*math.Cos(m.dec)*math.Cos(s.ra-m.ra))
+	inc := math.Atan2(sunDistanceKm*math.Sin(phi), m.dist-sunDistanceKm*math.Cos(phi))
+	angle := math.Atan2(math.Cos(s.dec)*math.Sin(s.ra-m.ra), math.Sin(s.dec)*math.Cos(m.dec)-math.Cos(s.dec)*math.Sin(m.dec)*math.Cos(s.ra-m.ra))
+
+	sign := 1.0
+	if angle < 0 {
+		sign = -1
+	}
+
+	return 0.5 + 0.5*inc*sign/math.Pi
+}
@@ -0,0 +1,180 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// nullableFields are the Forecast data fields, by OpenWeather JSON path,
+// written as null in nullable mode when no upstream value backs them.
+var nullableFields = []string{
+	"main.temp",
+	"main.feels_like",
+	"main.temp_min",
+	"main.temp_max",
+	"main.pressure",
+	"main.sea_level",
+	"main.grnd_level",
+	"main.humidity",
+	"main.temp_kf",
+	"main.pressure_change",
+	"weather",
+	"clouds.all",
+	"visibility",
+	"pop",
+	"wind.speed",
+	"wind.deg",
+	"wind.gust",
+	"rain.3h",
+	"uvi",
+	"uv.max",
+	"solar.radiation",
+	"solar.radiation_sum",
+	"solar.sunshine_duration",
+	"derived.dew_point",
+	"derived.heat_index",
+	"derived.wind_chill",
+	"derived.humidex",
+	"derived.wet_bulb",
+}
+
+// SetNullable switches forecasts to nullable output: fields without an
+// upstream value marshal as null instead of 0, unless the parser
+// approximated them.
+func (p *Parser) SetNullable(enabled bool) *Parser {
+	p.nullable = enabled
+	return p
+}
+
+// ValidationReport lists the fields the parser requests from Open-Meteo
+// that had no value in the forecast, by OpenWeather JSON path. Approximated
+// lists those of them the parser computed itself.
+type ValidationReport struct {
+	Missing      []string `json:"missing"`
+	Approximated []string `json:"approximated,omitempty"`
+}
+
+func (r ValidationReport) Complete() bool {
+	return len(r.Missing) == 0
+}
+
+// Validate reports which requested fields were absent upstream.
+func (f Forecast) Validate() ValidationReport {
+	report := ValidationReport{Missing: []string{}}
+
+	for field := range preferredResolution {
+		if _, ok := f.sources[field]; ok {
+			continue
+		}
+
+		report.Missing = append(report.Missing, field)
+		if _, ok := f.approximated[field]; ok {
+			report.Approximated = append(report.Approximated, field)
+		}
+	}
+
+	sort.Strings(report.Missing)
+	sort.Strings(report.Approximated)
+
+	return report
+}
+
+func (f Forecast) MarshalJSON() ([]byte, error) {
+	type Alias Forecast
+
+	if !f.nullable {
+		return json.Marshal(Alias(f))
+	}
+
+	paths := make(map[string]string, len(nullableFields))
+	for _, field := range nullableFields {
+		paths[field] = field
+	}
+
+	return marshalNullable(Alias(f), f.sources, f.approximated, paths)
+}
+
+// currentWeatherFields maps the nullable Forecast fields a CurrentWeather
+// carries to their path in it.
+var currentWeatherFields = func() map[string]string {
+	paths := map[string]string{"rain.3h": "rain.1h"}
+	for _, field := range nullableFields {
+		switch prefix, _, _ := strings.Cut(field, "."); prefix {
+		case "main", "wind", "weather", "clouds", "visibility":
+			paths[field] = field
+		}
+	}
+
+	return paths
+}()
+
+func (c CurrentWeather) MarshalJSON() ([]byte, error) {
+	type Alias CurrentWeather
+
+	if !c.nullable {
+		return json.Marshal(Alias(c))
+	}
+
+	return marshalNullable(Alias(c), c.sources, c.approximated, currentWeatherFields)
+}
+
+// marshalNullable marshals v, writing the fields of paths that are neither
+// sourced nor approximated as null. paths maps each Forecast field to its
+// path in v.
+func marshalNullable(v any, sources, approximated fieldSources, paths map[string]string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	for field, path := range paths {
+		if _, ok := sources[field]; ok {
+			continue
+		}
+		if _, ok := approximated[field]; ok {
+			continue
+		}
+
+		if err := setNull(obj, strings.Split(path, ".")); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(obj)
+}
+
+func setNull(obj map[string]json.RawMessage, path []string) error {
+	if len(path) == 1 {
+		obj[path[0]] = json.RawMessage("null")
+		return nil
+	}
+
+	// an omitted object, such as a nil Derived, has nothing to null
+	raw, ok := obj[path[0]]
+	if !ok {
+		return nil
+	}
+
+	var child map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &child); err != nil {
+		return err
+	}
+
+	if err := setNull(child, path[1:]); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(child)
+	if err != nil {
+		return err
+	}
+
+	obj[path[0]] = data
+	return nil
+}
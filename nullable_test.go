@@ -0,0 +1,185 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+func TestParser_SetNullable(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		nullable bool
+		wantNull bool
+	}{
+		{name: "Test default zeros", nullable: false, wantNull: false},
+		{name: "Test nullable", nullable: true, wantNull: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser("xxx", "https://ddd.cloudfront.net").SetNullable(tt.nullable)
+			p.now = fakeNow
+			p.om = &fakeOpenMeteo{}
+
+			forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := json.Marshal(forecast)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got struct {
+				Main struct {
+					Temp      *float64 `json:"temp"`
+					FeelsLike *float64 `json:"feels_like"`
+					Humidity  *int     `json:"humidity"`
+				} `json:"main"`
+				Visibility *int `json:"visibility"`
+				Dt         int  `json:"dt"`
+			}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Main.Temp == nil || *got.Main.Temp != 27.5 || got.Dt != int(startTime.Unix()) {
+				t.Errorf("populated fields lost: %s", data)
+			}
+
+			isNull := got.Main.Humidity == nil && got.Visibility == nil
+			if isNull != tt.wantNull {
+				t.Errorf("missing fields null = %v, want %v: %s", isNull, tt.wantNull, data)
+			}
+
+			// the approximated feels-like temperature is kept
+			if got.Main.FeelsLike == nil || *got.Main.FeelsLike != 27.5 {
+				t.Errorf("approximated feels_like lost: %s", data)
+			}
+
+			// the current weather envelope keeps the setting
+			data, err = json.Marshal(NewCurrentWeather(forecast))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got.Main.Temp, got.Main.Humidity, got.Visibility = nil, nil, nil
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Main.Temp == nil || (got.Main.Humidity == nil && got.Visibility == nil) != tt.wantNull {
+				t.Errorf("current weather missing fields null != %v: %s", tt.wantNull, data)
+			}
+		})
+	}
+}
+
+func TestParser_NullSamples(t *testing.T) {
+	// a model without temperatures or weather codes sends them as null
+	body := strings.NewReplacer(
+		`"temperature_2m": [27.1, 27.5, 28.0]`, `"temperature_2m": [27.1, null, 28.0]`,
+		`"weather_code": [1, 3, 61]`, `"weather_code": [1, null, 61]`,
+	).Replace(fakeForecastJSON)
+
+	p, _ := newFakeParser(body)
+	p.SetNullable(true)
+
+	forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the daily weather code stands in for the null hourly one
+	if forecast.sources["weather"] != ResolutionDaily || forecast.Weather[0].Main != "Rain" {
+		t.Errorf("weather %+v from %v", forecast.Weather, forecast.sources["weather"])
+	}
+
+	data, err := json.Marshal(forecast)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Main struct {
+			Temp    *float64 `json:"temp"`
+			TempMax *float64 `json:"temp_max"`
+		} `json:"main"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Main.Temp != nil || got.Main.TempMax == nil {
+		t.Errorf("null temperature not written as null: %s", data)
+	}
+
+	if missing := forecast.Validate().Missing; !slices.Contains(missing, "main.temp") {
+		t.Errorf("Validate() missing %v, want main.temp", missing)
+	}
+}
+
+func TestForecast_Validate(t *testing.T) {
+	p := NewParser("xxx", "https://ddd.cloudfront.net")
+	p.now = fakeNow
+	p.om = &fakeOpenMeteo{}
+
+	forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"main.feels_like", "main.humidity"}
+	got := forecast.Validate()
+	if !reflect.DeepEqual(got.Missing, want) || got.Complete() {
+		t.Errorf("Validate() = %+v, want missing %v", got, want)
+	}
+
+	if want := []string{"main.feels_like"}; !reflect.DeepEqual(got.Approximated, want) {
+		t.Errorf("Validate() approximated %v, want %v", got.Approximated, want)
+	}
+}
+
+func TestParser_MissingTimestamp(t *testing.T) {
+	p := Parser{}
+
+	_, err := p.forecastFromResponse(&pom.ForecastResponse{}, time.Now())
+	if !errors.Is(err, ErrNoForecastAtTime) {
+		t.Errorf("empty response err = %v, want ErrNoForecastAtTime", err)
+	}
+
+	forecast, _ := parseForecast(pom.NearestForecast{})
+	if _, ok := forecast.sources["dt"]; ok {
+		t.Error("dt recorded for a forecast without samples")
+	}
+
+	temp := 20.0
+	for _, nf := range []pom.NearestForecast{
+		{},
+		{HourlyForecast: &pom.NearestHourlyForecast{Temperature2m: &temp}},
+	} {
+		if _, err := ParseNearestForecast(nf); !errors.Is(err, ErrMissingTimestamp) {
+			t.Errorf("ParseNearestForecast(%+v) err = %v, want ErrMissingTimestamp", nf, err)
+		}
+	}
+
+	hourly := &pom.NearestHourlyForecast{Temperature2m: &temp}
+	hourly.Time.Time = time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+	if forecast, err := ParseNearestForecast(pom.NearestForecast{HourlyForecast: hourly}); err != nil || forecast.Dt != 1714525200 {
+		t.Errorf("ParseNearestForecast = %+v, %v", forecast, err)
+	}
+
+	if got := ToOpenWeatherError(ErrMissingTimestamp).Cod; got != "502" {
+		t.Errorf("ErrMissingTimestamp maps to %s, want 502", got)
+	}
+}
@@ -7,15 +7,20 @@ import (
 	"time"
 )
 
+// DtTxtLayout is the layout OpenWeather uses for dt_txt, always in UTC.
+const DtTxtLayout = "2006-01-02 15:04:05"
+
 type Response3HoursStepForecast struct {
 	Cod     string     `json:"cod"`
 	Message int        `json:"message"`
 	Cnt     int        `json:"cnt"`
 	List    []Forecast `json:"list"`
+	City    City       `json:"city"`
 }
 
 type ResponseAQI struct {
-	List []AQI `json:"list"`
+	Coord Coord `json:"coord"`
+	List  []AQI `json:"list"`
 }
 
 type AQI struct {
@@ -32,7 +37,9 @@ type AQI struct {
 		Pm10  float64 `json:"pm10"`
 		Nh3   float64 `json:"nh3"`
 	}
-	Dt int `json:"dt"`
+	Uvi    float64 `json:"uvi"`
+	Pollen *Pollen `json:"pollen,omitempty"`
+	Dt     int     `json:"dt"`
 }
 
 type AQIBuilder struct {
@@ -90,6 +97,16 @@ func (b *AQIBuilder) SetNh3(nh3 float64) *AQIBuilder {
 	return b
 }
 
+func (b *AQIBuilder) SetUvi(uvi float64) *AQIBuilder {
+	b.AQI.Uvi = uvi
+	return b
+}
+
+func (b *AQIBuilder) SetPollen(pollen *Pollen) *AQIBuilder {
+	b.AQI.Pollen = pollen
+	return b
+}
+
 func (b *AQIBuilder) SetDt(dt int) *AQIBuilder {
 	b.AQI.Dt = dt
 	return b
@@ -155,6 +172,33 @@ type (
 		Sys        Sys       `json:"sys"`
 		Rain       Rain      `json:"rain"`
 		DtTxt      string    `json:"dt_txt"`
+
+		// City is the forecast's location. OpenWeather's forecasts have no
+		// city, so it is only serialized by the envelopes built from it,
+		// such as CurrentWeather.
+		City *City `json:"-"`
+
+		// Uvi is only set when enabled with SetUVIndex. UV and Solar are nil
+		// when the forecast has none of their values.
+		Uvi   *float64 `json:"uvi,omitempty"`
+		UV    *UV      `json:"uv,omitempty"`
+		Solar *Solar   `json:"solar,omitempty"`
+
+		// Derived is nil when the forecast has no temperature, or neither
+		// humidity nor wind to derive from.
+		Derived *Derived `json:"derived,omitempty"`
+
+		// Provenance is only set when enabled with SetProvenance.
+		Provenance map[string]FieldProvenance `json:"provenance,omitempty"`
+
+		sources fieldSources
+		// approximated holds the fields the parser computed in place of a
+		// missing upstream value, with the resolution of their inputs. They
+		// are kept out of sources: nullable output keeps their value,
+		// provenance marks it approximated and Validate reports the
+		// upstream value as missing.
+		approximated fieldSources
+		nullable     bool
 	}
 
 	Main struct {
@@ -167,9 +211,16 @@ type (
 		GrndLevel int     `json:"grnd_level"`
 		Humidity  int     `json:"humidity"`
 		TempKf    float64 `json:"temp_kf"`
-		Location  string  `json:"location"`
-		Lat       string  `json:"lat"`
-		Lng       string  `json:"lng"`
+
+		// PressureChange is the sea level pressure change in hPa over the
+		// last three hours, and PressureTendency its direction. Both are
+		// unset when either sample is missing.
+		PressureChange   *float64 `json:"pressure_change,omitempty"`
+		PressureTendency string   `json:"pressure_tendency,omitempty"`
+
+		Location string `json:"location"`
+		Lat      string `json:"lat"`
+		Lng      string `json:"lng"`
 	}
 
 	Weather struct {
@@ -183,15 +234,50 @@ type (
 		All int `json:"all"`
 	}
 
+	// Wind speeds are in the unit system, while Beaufort, Description and
+	// Direction describe the wind whatever the units.
 	Wind struct {
-		Speed float64 `json:"speed"`
-		Deg   int     `json:"deg"`
+		Speed       float64 `json:"speed"`
+		Deg         int     `json:"deg"`
+		Gust        float64 `json:"gust"`
+		Beaufort    int     `json:"beaufort"`
+		Description string  `json:"description,omitempty"`
+		Direction   string  `json:"direction,omitempty"`
 	}
 
 	Sys struct {
 		Pod string `json:"pod"`
 	}
 
+	// UV holds the day's maximum UV index, with the category and sun
+	// protection advice for it.
+	UV struct {
+		Max      float64 `json:"max"`
+		Category string  `json:"category,omitempty"`
+		Advice   string  `json:"advice,omitempty"`
+	}
+
+	// Derived holds quantities computed from the temperature, humidity and
+	// wind, in the temperature unit. The wind chill needs the wind and the
+	// others the humidity; those without their input are zero, or null in
+	// nullable mode.
+	Derived struct {
+		DewPoint  float64 `json:"dew_point"`
+		HeatIndex float64 `json:"heat_index"`
+		WindChill float64 `json:"wind_chill"`
+		Humidex   float64 `json:"humidex"`
+		WetBulb   float64 `json:"wet_bulb"`
+	}
+
+	// Solar holds the global horizontal radiation in W/m² at the forecast
+	// time, and the day's radiation sum in MJ/m² and sunshine duration in
+	// seconds.
+	Solar struct {
+		Radiation        float64 `json:"radiation"`
+		RadiationSum     float64 `json:"radiation_sum"`
+		SunshineDuration float64 `json:"sunshine_duration"`
+	}
+
 	Rain struct {
 		ThreeH float64 `json:"3h"`
 	}
@@ -200,13 +286,12 @@ type (
 		ThreeH float64 `json:"3h"`
 	}
 
+	// City's coordinates are serialized as "coord", as OpenWeather does.
+	// Releases before the proxy serialized them as "Coord".
 	City struct {
-		ID    int    `json:"id"`
-		Name  string `json:"name"`
-		Coord struct {
-			Lat float64 `json:"lat"`
-			Lon float64 `json:"lon"`
-		}
+		ID         int    `json:"id"`
+		Name       string `json:"name"`
+		Coord      Coord  `json:"coord"`
 		Country    string `json:"country"`
 		Timezone   int    `json:"timezone"`
 		Sunrise    int    `json:"sunrise"`
@@ -214,12 +299,145 @@ type (
 		Population int    `json:"population"`
 	}
 
+	Coord struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	}
+
+	// CurrentWeather matches OpenWeather's /data/2.5/weather response.
+	CurrentWeather struct {
+		Coord      Coord       `json:"coord"`
+		Weather    []Weather   `json:"weather"`
+		Base       string      `json:"base"`
+		Main       Main        `json:"main"`
+		Visibility int         `json:"visibility"`
+		Wind       Wind        `json:"wind"`
+		Rain       CurrentRain `json:"rain"`
+		Clouds     Clouds      `json:"clouds"`
+		Dt         int         `json:"dt"`
+		Sys        CurrentSys  `json:"sys"`
+		Timezone   int         `json:"timezone"`
+		ID         int         `json:"id"`
+		Name       string      `json:"name"`
+		Cod        int         `json:"cod"`
+
+		sources      fieldSources
+		approximated fieldSources
+		nullable     bool
+	}
+
+	// ResponseDailyForecast matches OpenWeather's /data/2.5/forecast/daily
+	// response.
+	ResponseDailyForecast struct {
+		City    City            `json:"city"`
+		Cod     string          `json:"cod"`
+		Message float64         `json:"message"`
+		Cnt     int             `json:"cnt"`
+		List    []DailyForecast `json:"list"`
+	}
+
+	// DailyForecast is one day of OpenWeather's 16 day daily forecast.
+	// Dt is local noon; Rain, Snow and Precipitation are daily sums in mm.
+	DailyForecast struct {
+		Dt              int            `json:"dt"`
+		Sunrise         int            `json:"sunrise"`
+		Sunset          int            `json:"sunset"`
+		Moonrise        int            `json:"moonrise"`
+		Moonset         int            `json:"moonset"`
+		MoonPhase       float64        `json:"moon_phase"`
+		Temp            DailyTemp      `json:"temp"`
+		FeelsLike       DailyFeelsLike `json:"feels_like"`
+		Pressure        int            `json:"pressure"`
+		Humidity        int            `json:"humidity"`
+		Weather         []Weather      `json:"weather"`
+		Speed           float64        `json:"speed"`
+		Deg             int            `json:"deg"`
+		Gust            float64        `json:"gust"`
+		Beaufort        int            `json:"beaufort"`
+		WindDescription string         `json:"wind_description,omitempty"`
+		WindDirection   string         `json:"wind_direction,omitempty"`
+		Clouds          int            `json:"clouds"`
+		Pop             float64        `json:"pop"`
+		Rain            float64        `json:"rain,omitempty"`
+		Snow            float64        `json:"snow,omitempty"`
+		Precipitation   float64        `json:"precipitation"`
+		Uvi             float64        `json:"uvi"`
+		UVCategory      string         `json:"uv_category,omitempty"`
+		UVAdvice        string         `json:"uv_advice,omitempty"`
+
+		// SolarRadiation is the day's sum in MJ/m², SunshineDuration in
+		// seconds.
+		SolarRadiation   float64 `json:"solar_radiation"`
+		SunshineDuration float64 `json:"sunshine_duration"`
+	}
+
+	DailyTemp struct {
+		Day   float64 `json:"day"`
+		Min   float64 `json:"min"`
+		Max   float64 `json:"max"`
+		Night float64 `json:"night"`
+		Eve   float64 `json:"eve"`
+		Morn  float64 `json:"morn"`
+	}
+
+	DailyFeelsLike struct {
+		Day   float64 `json:"day"`
+		Night float64 `json:"night"`
+		Eve   float64 `json:"eve"`
+		Morn  float64 `json:"morn"`
+	}
+
+	CurrentRain struct {
+		OneH float64 `json:"1h"`
+	}
+
+	CurrentSys struct {
+		Country string `json:"country"`
+		Sunrise int    `json:"sunrise"`
+		Sunset  int    `json:"sunset"`
+	}
+
 	Error struct {
 		Cod     string `json:"cod"`
 		Message string `json:"message"`
 	}
 )
 
+func (e *Error) Error() string {
+	return e.Cod + ": " + e.Message
+}
+
+func NewCurrentWeather(f *Forecast) *CurrentWeather {
+	current := &CurrentWeather{
+		Weather:      f.Weather,
+		Base:         "stations",
+		Main:         f.Main,
+		Visibility:   f.Visibility,
+		Wind:         f.Wind,
+		Rain:         CurrentRain{OneH: f.Rain.ThreeH},
+		Clouds:       f.Clouds,
+		Dt:           f.Dt,
+		Cod:          200,
+		sources:      f.sources,
+		approximated: f.approximated,
+		nullable:     f.nullable,
+	}
+
+	if city := f.City; city != nil {
+		current.Coord = city.Coord
+		current.Sys = CurrentSys{
+			Country: city.Country,
+			Sunrise: city.Sunrise,
+			Sunset:  city.Sunset,
+		}
+		current.Timezone = city.Timezone
+		current.ID = city.ID
+		current.Name = city.Name
+	}
+
+	return current
+}
+
 func (w *Weather) MarshalJSON() ([]byte, error) {
 	type Alias Weather
 
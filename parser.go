@@ -2,24 +2,51 @@ package open_meteo_parser
 
 import (
 	"fmt"
+	go_http "github.com/saktibimantara/go-http"
 	pom "github.com/saktibimantara/go-open-meteo"
+	"math"
+	"net/url"
 	"time"
 )
 
 type Parser struct {
-	APIKey        string
-	CloudfrontURL string
-	om            pom.IGoOpenMeteo
+	APIKey          string
+	CloudfrontURL   string
+	om              pom.IGoOpenMeteo
+	config          *pom.Config
+	callApi         go_http.CallAPI
+	batch           batchConfig
+	timezone        string
+	geocoder        ReverseGeocoder
+	cityGeocoder    Geocoder
+	resilience      *resilience
+	limiter         *rateLimiter
+	flights         *flightGroup
+	instrumentation Instrumentation
+	provenance      bool
+	nullable        bool
+	uvIndex         bool
+	pollen          bool
+	ensemblePop     bool
+	model           Model
+	now             func() time.Time
 }
 
 func NewParser(apiKey, cloudfrontURL string) *Parser {
 
-	om := pom.New(pom.NewConfig())
+	config := pom.NewConfig()
+	callApi := newHTTPCaller(defaultHTTPTimeout)
 
 	return &Parser{
 		APIKey:        apiKey,
 		CloudfrontURL: cloudfrontURL,
-		om:            om,
+		om:            newOpenMeteoClient(config, callApi),
+		config:        config,
+		callApi:       callApi,
+		batch:         defaultBatchConfig(),
+		timezone:      TimezoneAuto,
+		flights:       &flightGroup{},
+		now:           time.Now,
 	}
 }
 
@@ -38,12 +65,13 @@ func (p Parser) GetOpenWeatherForecast(latitude, longitude float64, startTime ti
 	return weatherForecast, nil
 }
 
-func generateAQIParam(lat, lon float64) *pom.AQIParams {
+func generateAQIParam(lat, lon float64, extra ...pom.AQIParam) *pom.AQIParams {
 	params, err := pom.NewAQIParamsBuilder().
-		SetLatitude(-8.68163896537287).
-		SetLongitude(115.19724863873421).
+		SetLatitude(lat).
+		SetLongitude(lon).
 		SetForecastDays(5).
 		AddHourlyParam(pom.PM10, pom.PM2_5, pom.PM2_5, pom.CarbonMonoxide, pom.NitrogenDioxide, pom.SulphurDioxide, pom.Ozone, pom.UVIndex, pom.USAQI).
+		AddHourlyParam(extra...).
 		Build()
 
 	if err != nil {
@@ -70,6 +98,7 @@ func GenerateParams(lat, lon float64) *pom.ForecastParams {
 			pom.SurfacePressure,
 			pom.PressureMSL,
 			pom.IsDay,
+			pom.ShortwaveRadiation,
 		).
 		AddMinutely15Param(
 			pom.Minutely15Temperature2m,
@@ -81,11 +110,17 @@ func GenerateParams(lat, lon float64) *pom.ForecastParams {
 			pom.Minutely15WindSpeed10m,
 			pom.Minutely15WindGusts10m,
 			pom.Minutely15ApparentTemperature,
+			pom.Minutely15ShortwaveRadiation,
 		).
 		AddDailyParam(
 			pom.DailyTemperature2mMax,
 			pom.DailyTemperature2mMin,
 			pom.DailyWeatherCode,
+			pom.DailySunrise,
+			pom.DailySunset,
+			pom.DailyUvIndexMax,
+			pom.DailyShortwaveRadiationSum,
+			pom.DailySunshineDuration,
 		).
 		Build()
 
@@ -96,6 +131,47 @@ func GenerateParams(lat, lon float64) *pom.ForecastParams {
 	return &params
 }
 
+// queryParams appends query values go-open-meteo has no builder option for.
+type queryParams struct {
+	params pom.IForecastParams
+	extra  url.Values
+}
+
+func (q queryParams) GetParams() string {
+	if len(q.extra) == 0 {
+		return q.params.GetParams()
+	}
+
+	return q.params.GetParams() + "&" + q.extra.Encode()
+}
+
+func (p Parser) forecastParams(lat, lon float64) (pom.IForecastParams, error) {
+	if err := validateCoordinates(lat, lon); err != nil {
+		return nil, err
+	}
+
+	params := GenerateParams(lat, lon)
+	if params == nil {
+		return nil, fmt.Errorf("%w: %f,%f", ErrInvalidCoordinates, lat, lon)
+	}
+
+	return queryParams{params: params, extra: p.forecastQuery()}, nil
+}
+
+// forecastQuery returns the parser's timezone and model settings as query
+// values for the forecast API.
+func (p Parser) forecastQuery() url.Values {
+	extra := url.Values{}
+	if p.timezone != "" {
+		extra.Set("timezone", p.timezone)
+	}
+	if p.model != "" && p.model != ModelBestMatch {
+		extra.Set("models", string(p.model))
+	}
+
+	return extra
+}
+
 func (p Parser) GetOpenWeatherAQI(latitude, longitude float64, startTime time.Time) (*AQI, error) {
 
 	aqi, err := p.getAQIWithOpenWeatherFormat(latitude, longitude, startTime)
@@ -108,48 +184,264 @@ func (p Parser) GetOpenWeatherAQI(latitude, longitude float64, startTime time.Ti
 
 func (p Parser) getAQIWithOpenWeatherFormat(lat, lon float64, startTime time.Time) (*AQI, error) {
 
-	aqi, err := p.om.GetAQI(generateAQIParam(lat, lon))
+	aqi, err := p.fetchAQI(lat, lon)
 	if err != nil {
 		return nil, err
 	}
 
+	return p.aqiFromResponse(aqi, startTime)
+}
+
+func (p Parser) fetchAQI(lat, lon float64) (*pom.AQIResponse, error) {
+
+	if err := validateCoordinates(lat, lon); err != nil {
+		return nil, err
+	}
+
+	var extra []pom.AQIParam
+	if p.pollen {
+		extra = pollenParams
+	}
+
+	params := generateAQIParam(lat, lon, extra...)
+	if params == nil {
+		return nil, fmt.Errorf("%w: %f,%f", ErrInvalidCoordinates, lat, lon)
+	}
+
+	key := p.config.GetAirQualityURL() + "?" + params.GetParams()
+	resp, err := p.flights.do(key, func() (any, error) {
+		var resp *pom.AQIResponse
+		done := p.start(OperationFetchAQI)
+		err := p.call(paramsWeight(params), func() (err error) {
+			resp, err = p.om.GetAQI(params)
+			return err
+		})
+		done(err)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pom.AQIResponse), nil
+}
+
+func (p Parser) aqiFromResponse(aqi *pom.AQIResponse, startTime time.Time) (*AQI, error) {
+
+	if aqi.Hourly == nil || !coversTime(startTime, time.Hour, aqi.Hourly.Time) {
+		return nil, ErrNoForecastAtTime
+	}
+
 	wd := pom.NewWeatherData().SetAQIForecastResponse(aqi)
 
 	wp := pom.NewWeatherProcessor(wd)
 
+	done := p.start(OperationLookup)
 	nf, err := wp.FindNearestAQIForecastByTime(startTime)
+	done(err)
 	if err != nil {
 		return nil, err
 	}
 
-	if nf == nil {
-		return nil, fmt.Errorf("forecast is nil")
+	if nf == nil || nf.AqiHourlyForecast == nil {
+		return nil, ErrNoForecastAtTime
+	}
+
+	if nf.AqiHourlyForecast.Time == nil {
+		return nil, ErrMissingTimestamp
 	}
 
-	return ParseToAQI(*nf.AqiHourlyForecast), err
+	done = p.start(OperationAQI)
+	parsed := ParseToAQI(*nf.AqiHourlyForecast)
+	done(nil)
+
+	return parsed, nil
 }
 
 func (p Parser) getWeatherWithOpenWeatherFormat(lat, lon float64, startTime time.Time) (*Forecast, error) {
 
-	openResp, err := p.om.Forecast(GenerateParams(lat, lon))
+	// past times are served from the archive once it has caught up, and
+	// from the forecast API's past days before that
+	age := p.clock().Sub(startTime)
+	if age > archiveDelay {
+		return p.GetOpenWeatherHistorical(lat, lon, startTime)
+	}
+
+	fetch := p.fetchForecast
+	if age > recentPast {
+		fetch = func(lat, lon float64) (*pom.ForecastResponse, error) {
+			return p.fetchPastForecast(lat, lon, startTime)
+		}
+	}
+
+	openResp, err := fetch(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := p.forecastFromResponse(openResp, startTime)
 	if err != nil {
 		return nil, err
 	}
 
+	p.completeForecast(forecast, lat, lon, startTime)
+
+	return forecast, nil
+}
+
+// completeForecast adds the optional UV index and ensemble probability of
+// precipitation to a forecast at startTime, each from its own request and
+// left unset when that fails, and then its location.
+func (p Parser) completeForecast(forecast *Forecast, lat, lon float64, startTime time.Time) {
+	if p.uvIndex {
+		if aqiResp, err := p.fetchAQI(lat, lon); err == nil {
+			p.fillUV(forecast, aqiResp, startTime)
+		}
+	}
+
+	if p.ensemblePop {
+		if ens, err := p.fetchEnsemble(lat, lon); err == nil {
+			ens.fillPop(forecast, startTime, time.Hour)
+		}
+	}
+
+	p.fillLocation(forecast, lat, lon)
+}
+
+func (p Parser) fetchForecast(lat, lon float64) (*pom.ForecastResponse, error) {
+
+	params, err := p.forecastParams(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.fetchForecastParams(params)
+}
+
+func (p Parser) fetchForecastParams(params pom.IForecastParams) (*pom.ForecastResponse, error) {
+	// concurrent identical requests share one fetch and one response, which
+	// must not be modified after prepareResponse
+	key := p.config.GetForecastURL() + "?" + params.GetParams()
+	resp, err := p.flights.do(key, func() (any, error) {
+		var resp *pom.ForecastResponse
+		done := p.start(OperationFetchForecast)
+		err := p.call(paramsWeight(params), func() (err error) {
+			resp, err = p.om.Forecast(params)
+			return err
+		})
+		done(err)
+		if err != nil {
+			return nil, err
+		}
+
+		prepareResponse(resp)
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pom.ForecastResponse), nil
+}
+
+// prepareResponse normalizes a decoded response once, before it is shared:
+// times are localized and empty series dropped so the nearest-time lookups
+// never index into them.
+func prepareResponse(resp *pom.ForecastResponse) {
+	localizeResponse(resp)
+
+	if resp.Minutely15 != nil && len(resp.Minutely15.Time) == 0 {
+		resp.Minutely15 = nil
+	}
+
+	if resp.Hourly != nil && len(resp.Hourly.Time) == 0 {
+		resp.Hourly = nil
+	}
+
+	if resp.Daily != nil && len(resp.Daily.Time) == 0 {
+		resp.Daily = nil
+	}
+}
+
+func (p Parser) forecastFromResponse(openResp *pom.ForecastResponse, startTime time.Time) (*Forecast, error) {
+
+	loc := responseLocation(openResp.Timezone, openResp.UTCOffsetSeconds)
+
+	if !responseCoversTime(openResp, startTime) {
+		return nil, ErrNoForecastAtTime
+	}
+
 	wd := pom.NewWeatherData().SetForecastResponse(openResp)
 
 	wp := pom.NewWeatherProcessor(wd)
 
+	done := p.start(OperationLookup)
 	nf, err := wp.FindNearestForecastByTime(startTime)
+	done(err)
 	if err != nil {
 		return nil, err
 	}
 
 	if nf == nil {
-		return nil, fmt.Errorf("forecast is nil")
+		return nil, ErrNoForecastAtTime
+	}
+
+	done = p.start(OperationParse)
+	forecast, sources := parseForecast(*nf)
+	done(nil)
+	p.reportFallbacks(sources)
+
+	if _, ok := sources["dt"]; !ok {
+		return nil, ErrMissingTimestamp
+	}
+
+	forecast.completePressure(openResp, *nf)
+	forecast.nullable = p.nullable
+
+	if p.provenance {
+		forecast.Provenance = provenance(sources, forecast.approximated, *nf, startTime)
+	}
+	forecast.City = &City{Timezone: utcOffset(forecast.GetDate(), loc)}
+	forecast.City.Coord.Lat = openResp.Latitude
+	forecast.City.Coord.Lon = openResp.Longitude
+
+	if daily := nf.DailyForecast; daily != nil {
+		forecast.City.Sunrise = parseLocalUnix(daily.Sunrise, loc)
+		forecast.City.Sunset = parseLocalUnix(daily.Sunset, loc)
 	}
 
-	return ParseToForecast(*nf), err
+	return forecast, err
+}
+
+// responseCoversTime reports whether any series in the response has a sample
+// within one step of t.
+func responseCoversTime(resp *pom.ForecastResponse, t time.Time) bool {
+	if resp.Minutely15 != nil && coversTime(t, 15*time.Minute, resp.Minutely15.Time) {
+		return true
+	}
+
+	if resp.Hourly != nil && coversTime(t, time.Hour, resp.Hourly.Time) {
+		return true
+	}
+
+	if resp.Daily != nil && len(resp.Daily.Time) > 0 {
+		first := resp.Daily.Time[0].Time
+		last := resp.Daily.Time[len(resp.Daily.Time)-1].Time
+		return !t.Before(first) && t.Before(last.AddDate(0, 0, 1))
+	}
+
+	return false
+}
+
+func coversTime(t time.Time, step time.Duration, times []pom.CustomTime) bool {
+	if len(times) == 0 {
+		return false
+	}
+
+	first := times[0].Time.Add(-step)
+	last := times[len(times)-1].Time.Add(step)
+
+	return t.After(first) && t.Before(last)
 }
 
 func ParseToAQI(aqi pom.NearestAQIHourlyForecast) *AQI {
@@ -162,13 +454,40 @@ func ParseToAQI(aqi pom.NearestAQIHourlyForecast) *AQI {
 		SetPm10(safeFloat64(aqi.PM10)).
 		SetPm2_5(safeFloat64(aqi.PM2_5)).
 		SetSo2(safeFloat64(aqi.SulphurDioxide)).
+		SetUvi(safeFloat64(aqi.UVIndex)).
+		SetPollen(parsePollen(aqi)).
 		SetDt(int(safeDate(aqi.Time).Unix())).
 		Build()
 
 	return aqiData
 }
 
+// ParseToForecast converts a nearest forecast sample to a Forecast.
+//
+// Deprecated: a sample without a timestamp is reported at the zero time. Use
+// ParseNearestForecast, which returns ErrMissingTimestamp instead.
 func ParseToForecast(forecast pom.NearestForecast) *Forecast {
+	f, _ := parseForecast(forecast)
+	return f
+}
+
+// ParseNearestForecast converts a nearest forecast sample to a Forecast. It
+// returns ErrMissingTimestamp when no series of the sample has a time.
+func ParseNearestForecast(forecast pom.NearestForecast) (*Forecast, error) {
+	f, sources := parseForecast(forecast)
+	if _, ok := sources["dt"]; !ok {
+		return nil, ErrMissingTimestamp
+	}
+
+	return f, nil
+}
+
+// parseForecast merges the minutely15, hourly and daily samples into one
+// forecast, preferring the finest resolution, and records where each field
+// came from.
+func parseForecast(forecast pom.NearestForecast) (*Forecast, fieldSources) {
+	sources := fieldSources{}
+
 	var temp *float64
 	var dt *time.Time
 	var feelsLike *float64
@@ -185,80 +504,125 @@ func ParseToForecast(forecast pom.NearestForecast) *Forecast {
 	var windGust *float64
 	var rain *float64
 	var isDay *int
+	var radiation *float64
+	var uvMax *float64
+	var radiationSum *float64
+	var sunshine *float64
 
+	// a sample whose time did not parse has the zero time, which is no
+	// timestamp at all
 	if forecast.Minutely15Forecast != nil {
-		dt = &forecast.Minutely15Forecast.Time.Time
-		temp = forecast.Minutely15Forecast.Temperature2m
-		feelsLike = forecast.Minutely15Forecast.ApparentTemperature
-		if weatherCode := forecast.Minutely15Forecast.WeatherCode; weatherCode != nil {
+		if t := &forecast.Minutely15Forecast.Time.Time; !t.IsZero() {
+			dt = t
+			sources["dt"] = ResolutionMinutely15
+		}
+
+		if temp = known(forecast.Minutely15Forecast.Temperature2m); temp != nil {
+			sources["main.temp"] = ResolutionMinutely15
+		}
+
+		if feelsLike = known(forecast.Minutely15Forecast.ApparentTemperature); feelsLike != nil {
+			sources["main.feels_like"] = ResolutionMinutely15
+		}
+
+		if weatherCode := knownWeatherCode(forecast.Minutely15Forecast.WeatherCode); weatherCode != nil {
 			weather = ParseWeatherCode(*weatherCode)
+			sources["weather"] = ResolutionMinutely15
 		}
 
-		if hm := forecast.Minutely15Forecast.RelativeHumidity2m; hm != nil {
+		if hm := known(forecast.Minutely15Forecast.RelativeHumidity2m); hm != nil {
 			humidity = new(int)
 			*humidity = int(*hm)
+			sources["main.humidity"] = ResolutionMinutely15
 		}
 
-		if ws := forecast.Minutely15Forecast.WindSpeed10m; ws != nil {
+		if ws := known(forecast.Minutely15Forecast.WindSpeed10m); ws != nil {
 			windSpeed = new(float64)
 			*windSpeed = *ws
+			sources["wind.speed"] = ResolutionMinutely15
 		}
 
-		if wd := forecast.Minutely15Forecast.WindDirection10m; wd != nil {
+		if wd := known(forecast.Minutely15Forecast.WindDirection10m); wd != nil {
 			windDeg = new(int)
 			*windDeg = int(*wd)
+			sources["wind.deg"] = ResolutionMinutely15
 		}
 
-		if wg := forecast.Minutely15Forecast.WindGusts10m; wg != nil {
+		if wg := known(forecast.Minutely15Forecast.WindGusts10m); wg != nil {
 			windGust = new(float64)
 			*windGust = *wg
+			sources["wind.gust"] = ResolutionMinutely15
+		}
+
+		if radiation = known(forecast.Minutely15Forecast.ShortwaveRadiation); radiation != nil {
+			sources["solar.radiation"] = ResolutionMinutely15
 		}
 
 	}
 
 	if forecast.HourlyForecast != nil {
-		if dt == nil {
-			dt = &forecast.HourlyForecast.Time.Time
+		if t := &forecast.HourlyForecast.Time.Time; dt == nil && !t.IsZero() {
+			dt = t
+			sources["dt"] = ResolutionHourly
 		}
 
 		if temp == nil {
-			temp = forecast.HourlyForecast.Temperature2m
+			if temp = known(forecast.HourlyForecast.Temperature2m); temp != nil {
+				sources["main.temp"] = ResolutionHourly
+			}
 		}
 
 		if weather == nil {
-			if weatherCode := forecast.HourlyForecast.WeatherCode; weatherCode != nil {
+			if weatherCode := knownWeatherCode(forecast.HourlyForecast.WeatherCode); weatherCode != nil {
 				weather = ParseWeatherCode(*weatherCode)
+				sources["weather"] = ResolutionHourly
 			}
 		}
 
-		if hm := forecast.HourlyForecast.RelativeHumidity2m; hm != nil && humidity == nil {
+		if hm := known(forecast.HourlyForecast.RelativeHumidity2m); hm != nil && humidity == nil {
 			humidity = new(int)
 			*humidity = int(*hm)
+			sources["main.humidity"] = ResolutionHourly
 		}
 
-		if ws := forecast.HourlyForecast.WindSpeed10m; ws != nil && windSpeed == nil {
+		if ws := known(forecast.HourlyForecast.WindSpeed10m); ws != nil && windSpeed == nil {
 			windSpeed = new(float64)
 			*windSpeed = *ws
+			sources["wind.speed"] = ResolutionHourly
 		}
 
-		if wd := forecast.HourlyForecast.WindDirection10m; wd != nil && windDeg == nil {
+		if wd := known(forecast.HourlyForecast.WindDirection10m); wd != nil && windDeg == nil {
 			windDeg = new(int)
 			*windDeg = int(*wd)
+			sources["wind.deg"] = ResolutionHourly
 		}
 
-		if wg := forecast.HourlyForecast.WindGusts10m; wg != nil && windGust == nil {
+		if wg := known(forecast.HourlyForecast.WindGusts10m); wg != nil && windGust == nil {
 			windGust = new(float64)
 			*windGust = *wg
+			sources["wind.gust"] = ResolutionHourly
 		}
 
-		if ps := forecast.HourlyForecast.PressureMSL; ps != nil {
+		if ps := known(forecast.HourlyForecast.PressureMSL); ps != nil {
 			pressure = new(int)
 			*pressure = int(*ps)
+			sources["main.pressure"] = ResolutionHourly
+
+			seaLevel = new(int)
+			*seaLevel = int(*ps)
+			sources["main.sea_level"] = ResolutionHourly
+		}
+
+		if sp := known(forecast.HourlyForecast.SurfacePressure); sp != nil {
+			grndLevel = new(int)
+			*grndLevel = int(*sp)
+			sources["main.grnd_level"] = ResolutionHourly
 		}
 
-		if rn := forecast.HourlyForecast.Rain; rn != nil {
+		if rn := known(forecast.HourlyForecast.Rain); rn != nil {
 			rain = new(float64)
 			*rain = *rn
+			sources["rain.3h"] = ResolutionHourly
 		}
 
 		if id := forecast.HourlyForecast.IsDay; id != nil {
@@ -266,32 +630,54 @@ func ParseToForecast(forecast pom.NearestForecast) *Forecast {
 			*isDay = *id
 		}
 
+		if radiation == nil {
+			if radiation = known(forecast.HourlyForecast.ShortwaveRadiation); radiation != nil {
+				sources["solar.radiation"] = ResolutionHourly
+			}
+		}
+
 	}
 
 	if forecast.DailyForecast != nil {
-		if dt == nil {
-			dt = &forecast.DailyForecast.Time.Time
+		if t := &forecast.DailyForecast.Time.Time; dt == nil && !t.IsZero() {
+			dt = t
+			sources["dt"] = ResolutionDaily
 		}
 
 		if weather == nil {
-			if weatherCode := forecast.DailyForecast.WeatherCode; weatherCode != nil {
+			if weatherCode := knownWeatherCode(forecast.DailyForecast.WeatherCode); weatherCode != nil {
 				weather = ParseWeatherCode(*weatherCode)
+				sources["weather"] = ResolutionDaily
 			}
 		}
 
-		if tmpMax := forecast.DailyForecast.Temperature2mMax; tmpMax != nil {
+		if tmpMax := known(forecast.DailyForecast.Temperature2mMax); tmpMax != nil {
 			tempMax = new(float64)
 			*tempMax = *tmpMax
+			sources["main.temp_max"] = ResolutionDaily
 		}
 
-		if tmpMin := forecast.DailyForecast.Temperature2mMin; tmpMin != nil {
+		if tmpMin := known(forecast.DailyForecast.Temperature2mMin); tmpMin != nil {
 			tempMin = new(float64)
 			*tempMin = *tmpMin
+			sources["main.temp_min"] = ResolutionDaily
+		}
+
+		if uvMax = known(forecast.DailyForecast.UvIndexMax); uvMax != nil {
+			sources["uv.max"] = ResolutionDaily
+		}
+
+		if radiationSum = known(forecast.DailyForecast.ShortwaveRadiationSum); radiationSum != nil {
+			sources["solar.radiation_sum"] = ResolutionDaily
+		}
+
+		if sunshine = known(forecast.DailyForecast.SunshineDuration); sunshine != nil {
+			sources["solar.sunshine_duration"] = ResolutionDaily
 		}
 
 	}
 
-	return &Forecast{
+	f := &Forecast{
 		Dt: int(safeDate(dt).Unix()),
 		Main: Main{
 			Temp:      safeFloat64(temp),
@@ -315,14 +701,52 @@ func ParseToForecast(forecast pom.NearestForecast) *Forecast {
 		Wind: Wind{
 			Speed: safeFloat64(windSpeed),
 			Deg:   safeInt(windDeg),
+			Gust:  safeFloat64(windGust),
 		},
 		Sys: Sys{},
 		Rain: Rain{
 			ThreeH: safeFloat64(rain),
 		},
-		DtTxt: safeDate(dt).String(),
+		DtTxt:   safeDate(dt).UTC().Format(DtTxtLayout),
+		sources: sources,
+	}
+
+	if uvMax != nil {
+		f.UV = &UV{Max: *uvMax}
+	}
+
+	if radiation != nil || radiationSum != nil || sunshine != nil {
+		f.Solar = &Solar{
+			Radiation:        safeFloat64(radiation),
+			RadiationSum:     safeFloat64(radiationSum),
+			SunshineDuration: safeFloat64(sunshine),
+		}
+	}
+
+	f.Wind.describe(sources)
+	f.derive(sources)
+	f.describeUV()
+
+	return f, sources
+}
+
+// known returns v, or nil when Open-Meteo sent it as null, which
+// decodeForecastResponse marks as NaN.
+func known(v *float64) *float64 {
+	if v == nil || math.IsNaN(*v) {
+		return nil
+	}
+
+	return v
+}
+
+// knownWeatherCode returns code, or nil when Open-Meteo sent it as null.
+func knownWeatherCode(code *pom.WeatherCodeResponse) *pom.WeatherCodeResponse {
+	if code == nil || *code == missingWeatherCode {
+		return nil
 	}
 
+	return code
 }
 
 func safeWeather(w *Weather, isDay *int) Weather {
@@ -0,0 +1,157 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"math"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+// Pollen levels, following the U.S. National Allergy Bureau's scales.
+const (
+	PollenLow      = "low"
+	PollenModerate = "moderate"
+	PollenHigh     = "high"
+	PollenVeryHigh = "very_high"
+)
+
+var pollenParams = []pom.AQIParam{
+	pom.AlderPollen,
+	pom.BirchPollen,
+	pom.GrassPollen,
+	pom.MugwortPollen,
+	pom.OlivePollen,
+	pom.RagweedPollen,
+}
+
+// pollenLimits are the grains/m³ at which the moderate, high and very high
+// levels start, for trees, grasses and weeds.
+var (
+	treePollenLimits  = [3]float64{15, 90, 1500}
+	grassPollenLimits = [3]float64{5, 20, 200}
+	weedPollenLimits  = [3]float64{10, 50, 500}
+)
+
+type (
+	// Pollen holds the pollen counts at a time. Open-Meteo only forecasts
+	// pollen for Europe during the pollen season; species it does not serve
+	// are nil.
+	Pollen struct {
+		Alder   *PollenCount `json:"alder,omitempty"`
+		Birch   *PollenCount `json:"birch,omitempty"`
+		Grass   *PollenCount `json:"grass,omitempty"`
+		Mugwort *PollenCount `json:"mugwort,omitempty"`
+		Olive   *PollenCount `json:"olive,omitempty"`
+		Ragweed *PollenCount `json:"ragweed,omitempty"`
+	}
+
+	// PollenCount is a species' pollen in grains/m³ and its level.
+	PollenCount struct {
+		Grains float64 `json:"grains"`
+		Level  string  `json:"level"`
+	}
+)
+
+// SetPollen controls whether air quality carries pollen counts. Pollen adds
+// six variables to the air quality request.
+func (p *Parser) SetPollen(enabled bool) *Parser {
+	p.pollen = enabled
+	return p
+}
+
+// TreePollenLevel returns the level of a tree pollen count, such as alder,
+// birch or olive, in grains/m³.
+func TreePollenLevel(grains float64) string {
+	return pollenLevel(grains, treePollenLimits)
+}
+
+// GrassPollenLevel returns the level of a grass pollen count in grains/m³.
+func GrassPollenLevel(grains float64) string {
+	return pollenLevel(grains, grassPollenLimits)
+}
+
+// WeedPollenLevel returns the level of a weed pollen count, such as mugwort
+// or ragweed, in grains/m³.
+func WeedPollenLevel(grains float64) string {
+	return pollenLevel(grains, weedPollenLimits)
+}
+
+func pollenLevel(grains float64, limits [3]float64) string {
+	switch {
+	case grains < limits[0]:
+		return PollenLow
+	case grains < limits[1]:
+		return PollenModerate
+	case grains < limits[2]:
+		return PollenHigh
+	default:
+		return PollenVeryHigh
+	}
+}
+
+func pollenCount(grains *float64, level func(float64) string) *PollenCount {
+	if grains == nil || math.IsNaN(*grains) {
+		return nil
+	}
+
+	return &PollenCount{Grains: *grains, Level: level(*grains)}
+}
+
+// parsePollen returns the pollen counts of an air quality sample, or nil
+// when it has none.
+func parsePollen(aqi pom.NearestAQIHourlyForecast) *Pollen {
+	pollen := Pollen{
+		Alder:   pollenCount(aqi.AlderPollen, TreePollenLevel),
+		Birch:   pollenCount(aqi.BirchPollen, TreePollenLevel),
+		Grass:   pollenCount(aqi.GrassPollen, GrassPollenLevel),
+		Mugwort: pollenCount(aqi.MugwortPollen, WeedPollenLevel),
+		Olive:   pollenCount(aqi.OlivePollen, TreePollenLevel),
+		Ragweed: pollenCount(aqi.RagweedPollen, WeedPollenLevel),
+	}
+
+	if pollen == (Pollen{}) {
+		return nil
+	}
+
+	return &pollen
+}
+
+// markMissingPollen sets the pollen values that data, the air quality
+// response resp was decoded from, has as null to NaN. go-open-meteo decodes
+// the series as []float64, which would turn the nulls Open-Meteo sends
+// outside Europe and past the end of its pollen forecast into counts of 0.
+func markMissingPollen(data []byte, resp *pom.AQIResponse) error {
+	if resp.Hourly == nil {
+		return nil
+	}
+
+	var raw struct {
+		Hourly map[string]json.RawMessage `json:"hourly"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	hourly := resp.Hourly
+	series := map[pom.AQIParam]*[]float64{
+		pom.AlderPollen:   &hourly.AlderPollen,
+		pom.BirchPollen:   &hourly.BirchPollen,
+		pom.GrassPollen:   &hourly.GrassPollen,
+		pom.MugwortPollen: &hourly.MugwortPollen,
+		pom.OlivePollen:   &hourly.OlivePollen,
+		pom.RagweedPollen: &hourly.RagweedPollen,
+	}
+
+	for param, values := range series {
+		data, ok := raw.Hourly[string(param)]
+		if !ok {
+			continue
+		}
+
+		if err := markNulls(data, *values, math.NaN()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package open_meteo_parser
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakePollenJSON = `{
+	"latitude": 48.14,
+	"longitude": 11.58,
+	"timezone": "GMT",
+	"hourly": {
+		"time": ["2024-05-01T00:00", "2024-05-01T01:00"],
+		"pm10": [12.5, 13.1],
+		"pm2_5": [6.2, 6.8],
+		"ozone": [60, 62],
+		"us_aqi": [30, 31],
+		"alder_pollen": [0, 0.2],
+		"birch_pollen": [85.4, 120.6],
+		"grass_pollen": [3.1, 24.5],
+		"mugwort_pollen": [0, 0],
+		"olive_pollen": [1.2, 2],
+		"ragweed_pollen": [0, 0]
+	}
+}`
+
+// fakeNullPollenJSON serves birch pollen from 01:00 only and no other
+// species, as Open-Meteo does outside Europe and past its pollen forecast.
+const fakeNullPollenJSON = `{
+	"latitude": -8.68,
+	"longitude": 115.2,
+	"timezone": "GMT",
+	"hourly": {
+		"time": ["2024-05-01T00:00", "2024-05-01T01:00"],
+		"pm10": [12.5, 13.1],
+		"pm2_5": [6.2, 6.8],
+		"ozone": [60, 62],
+		"us_aqi": [30, 31],
+		"alder_pollen": [null, null],
+		"birch_pollen": [null, 12.4],
+		"grass_pollen": [null, null],
+		"mugwort_pollen": [null, null],
+		"olive_pollen": [null, null]
+	}
+}`
+
+func TestPollenLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  func(float64) string
+		grains float64
+		want   string
+	}{
+		{"tree", TreePollenLevel, 0, PollenLow},
+		{"tree", TreePollenLevel, 14.9, PollenLow},
+		{"tree", TreePollenLevel, 15, PollenModerate},
+		{"tree", TreePollenLevel, 90, PollenHigh},
+		{"tree", TreePollenLevel, 1500, PollenVeryHigh},
+		{"grass", GrassPollenLevel, 4, PollenLow},
+		{"grass", GrassPollenLevel, 19, PollenModerate},
+		{"grass", GrassPollenLevel, 20, PollenHigh},
+		{"grass", GrassPollenLevel, 200, PollenVeryHigh},
+		{"weed", WeedPollenLevel, 9, PollenLow},
+		{"weed", WeedPollenLevel, 10, PollenModerate},
+		{"weed", WeedPollenLevel, 499, PollenHigh},
+		{"weed", WeedPollenLevel, 500, PollenVeryHigh},
+	}
+
+	for _, tt := range tests {
+		if got := tt.level(tt.grains); got != tt.want {
+			t.Errorf("%s pollen level of %v = %q, want %q", tt.name, tt.grains, got, tt.want)
+		}
+	}
+}
+
+func TestParser_Pollen(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	t.Run("Test enabled", func(t *testing.T) {
+		p, caller := newFakeParser(fakePollenJSON)
+		p.SetPollen(true)
+
+		aqi, err := p.GetOpenWeatherAQI(48.14, 11.58, startTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if aqi.Pollen == nil {
+			t.Fatal("no pollen")
+		}
+
+		if got := *aqi.Pollen.Birch; got != (PollenCount{Grains: 120.6, Level: PollenHigh}) {
+			t.Errorf("birch = %+v", got)
+		}
+
+		if got := *aqi.Pollen.Grass; got != (PollenCount{Grains: 24.5, Level: PollenHigh}) {
+			t.Errorf("grass = %+v", got)
+		}
+
+		if got := *aqi.Pollen.Ragweed; got != (PollenCount{Grains: 0, Level: PollenLow}) {
+			t.Errorf("ragweed = %+v", got)
+		}
+
+		u, _ := url.Parse(caller.calls()[0])
+		hourly := u.Query().Get("hourly")
+		for _, param := range pollenParams {
+			if !strings.Contains(hourly, string(param)) {
+				t.Errorf("%s not requested in %q", param, hourly)
+			}
+		}
+	})
+
+	t.Run("Test disabled", func(t *testing.T) {
+		p, caller := newFakeParser(fakePollenJSON)
+
+		if _, err := p.GetOpenWeatherAQI(48.14, 11.58, startTime); err != nil {
+			t.Fatal(err)
+		}
+
+		u, _ := url.Parse(caller.calls()[0])
+		if hourly := u.Query().Get("hourly"); strings.Contains(hourly, "pollen") {
+			t.Errorf("pollen requested in %q", hourly)
+		}
+	})
+
+	t.Run("Test null series", func(t *testing.T) {
+		p, _ := newFakeParser(fakeNullPollenJSON)
+		p.SetPollen(true)
+
+		aqi, err := p.GetOpenWeatherAQI(-8.68, 115.2, startTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if aqi.Pollen == nil || aqi.Pollen.Birch == nil || *aqi.Pollen.Birch != (PollenCount{Grains: 12.4, Level: PollenLow}) {
+			t.Fatalf("pollen = %+v, want birch only", aqi.Pollen)
+		}
+
+		if got := *aqi.Pollen; got.Alder != nil || got.Grass != nil || got.Mugwort != nil || got.Olive != nil || got.Ragweed != nil {
+			t.Errorf("null or absent species served: %+v", got)
+		}
+
+		// no species has a count at 00:00
+		aqi, err = p.GetOpenWeatherAQI(-8.68, 115.2, startTime.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if aqi.Pollen != nil {
+			t.Errorf("pollen = %+v, want nil", aqi.Pollen)
+		}
+	})
+
+	t.Run("Test not served", func(t *testing.T) {
+		p := NewParser("xxx", "https://ddd.cloudfront.net").SetPollen(true)
+		p.om = &fakeOpenMeteo{}
+
+		aqi, err := p.GetOpenWeatherAQI(-8.68, 115.2, startTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if aqi.Pollen != nil {
+			t.Errorf("pollen = %+v, want nil", aqi.Pollen)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package open_meteo_parser
+
+import (
+	"math"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+// Pressure tendencies over the last three hours.
+const (
+	PressureRising  = "rising"
+	PressureFalling = "falling"
+	PressureSteady  = "steady"
+)
+
+const (
+	pressureTendencyWindow = 3 * time.Hour
+
+	// pressureSteadyBand is the change in hPa over the window still
+	// reported as steady.
+	pressureSteadyBand = 1.0
+)
+
+// completePressure fills whichever of the sea and ground level pressures is
+// missing from the other, reduced with the barometric formula at the
+// response's elevation, and sets the pressure tendency from the hourly
+// series. go-open-meteo's minutely15 series carries no pressure, so both
+// come from the hourly sample. A reduced level is recorded as approximated,
+// not as Open-Meteo's.
+func (f *Forecast) completePressure(resp *pom.ForecastResponse, nf pom.NearestForecast) {
+	hourly := nf.HourlyForecast
+	if hourly == nil {
+		return
+	}
+
+	if _, ok := f.sources["main.temp"]; ok {
+		msl, surface := known(hourly.PressureMSL), known(hourly.SurfacePressure)
+		switch {
+		case msl != nil && surface == nil:
+			f.Main.GrndLevel = int(reduceToGround(*msl, f.Main.Temp, resp.Elevation))
+			f.approximate("main.grnd_level", ResolutionHourly)
+		case surface != nil && msl == nil:
+			f.Main.SeaLevel = int(reduceToSeaLevel(*surface, f.Main.Temp, resp.Elevation))
+			f.approximate("main.sea_level", ResolutionHourly)
+		}
+	}
+
+	if change, ok := pressureChange(resp.Hourly, hourly.Time.Time); ok {
+		f.Main.PressureChange = &change
+		f.Main.PressureTendency = PressureTendency(change)
+		f.sources["main.pressure_change"] = ResolutionHourly
+	}
+}
+
+// PressureTendency classifies a sea level pressure change in hPa over three
+// hours.
+func PressureTendency(change float64) string {
+	switch {
+	case change > pressureSteadyBand:
+		return PressureRising
+	case change < -pressureSteadyBand:
+		return PressureFalling
+	default:
+		return PressureSteady
+	}
+}
+
+// pressureChange returns the sea level pressure change in the three hours up
+// to t, rounded to 0.1 hPa.
+func pressureChange(hourly *pom.HourlyResponse, t time.Time) (float64, bool) {
+	if hourly == nil {
+		return 0, false
+	}
+
+	now, before := -1, -1
+	for i, ht := range hourly.Time {
+		switch {
+		case ht.Time.Equal(t):
+			now = i
+		case ht.Time.Equal(t.Add(-pressureTendencyWindow)):
+			before = i
+		}
+	}
+
+	pNow, pBefore := valueAt(hourly.PressureMSL, now), valueAt(hourly.PressureMSL, before)
+	if pNow == nil || pBefore == nil {
+		return 0, false
+	}
+
+	return math.Round((*pNow-*pBefore)*10) / 10, true
+}
+
+// barometricExponent is g·M/(R·L) of the international barometric formula.
+const barometricExponent = 5.257
+
+// reduceToSeaLevel reduces a station pressure in hPa at elevation metres to
+// sea level, with the temperature in °C.
+func reduceToSeaLevel(ground, celsius, elevation float64) float64 {
+	return ground * math.Pow(1-0.0065*elevation/(celsius+0.0065*elevation+273.15), -barometricExponent)
+}
+
+// reduceToGround is the inverse of reduceToSeaLevel.
+func reduceToGround(seaLevel, celsius, elevation float64) float64 {
+	return seaLevel * math.Pow(1-0.0065*elevation/(celsius+0.0065*elevation+273.15), barometricExponent)
+}
@@ -0,0 +1,117 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakePressureJSON = `{
+	"latitude": -8.25,
+	"longitude": 115.3,
+	"elevation": 1000,
+	"timezone": "GMT",
+	"hourly": {
+		"time": ["2024-05-01T00:00", "2024-05-01T01:00", "2024-05-01T02:00", "2024-05-01T03:00", "2024-05-01T04:00"],
+		"temperature_2m": [8.5, 8.5, 8.5, 8.5, 8.5],
+		"pressure_msl": [1012.0, 1011.6, 1010.9, 1011.2, 1013.3],
+		"surface_pressure": [897.6, 897.2, 896.6, 896.9]
+	}
+}`
+
+func TestPressureTendency(t *testing.T) {
+	tests := []struct {
+		change float64
+		want   string
+	}{
+		{0, PressureSteady},
+		{1, PressureSteady},
+		{-1, PressureSteady},
+		{1.1, PressureRising},
+		{-3.5, PressureFalling},
+	}
+
+	for _, tt := range tests {
+		if got := PressureTendency(tt.change); got != tt.want {
+			t.Errorf("PressureTendency(%v) = %q, want %q", tt.change, got, tt.want)
+		}
+	}
+}
+
+func TestReducePressure(t *testing.T) {
+	// standard atmosphere at 1000 m
+	if got := reduceToSeaLevel(898.75, 8.5, 1000); math.Abs(got-1013.25) > 0.1 {
+		t.Errorf("reduceToSeaLevel = %v, want 1013.25", got)
+	}
+
+	if got := reduceToGround(1013.25, 8.5, 1000); math.Abs(got-898.75) > 0.1 {
+		t.Errorf("reduceToGround = %v, want 898.75", got)
+	}
+
+	if got := reduceToSeaLevel(1000, 20, 0); got != 1000 {
+		t.Errorf("reduceToSeaLevel at sea level = %v, want 1000", got)
+	}
+}
+
+func TestParser_Pressure(t *testing.T) {
+	p, _ := newFakeParser(fakePressureJSON)
+	p.SetNullable(true).SetProvenance(true)
+
+	list, err := p.GetOpenWeatherForecastList(-8.25, 115.3, fakeNow(), time.Hour, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := list.List[0].Main
+	if first.SeaLevel != 1012 || first.GrndLevel != 897 || first.Pressure != 1012 {
+		t.Errorf("sea level %d, ground level %d, pressure %d", first.SeaLevel, first.GrndLevel, first.Pressure)
+	}
+
+	if first.PressureChange != nil {
+		t.Errorf("change %v without three hours of history", *first.PressureChange)
+	}
+
+	if first.PressureTendency != "" {
+		t.Errorf("tendency %q without three hours of history", first.PressureTendency)
+	}
+
+	// an unknown change is not reported as steady
+	data, err := json.Marshal(list.List[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"pressure_change":null`) {
+		t.Errorf("unknown pressure change not null: %s", data)
+	}
+
+	if got := list.List[3].Main; got.PressureChange == nil || *got.PressureChange != -0.8 || got.PressureTendency != PressureSteady {
+		t.Errorf("03:00 change %v, tendency %q", got.PressureChange, got.PressureTendency)
+	}
+
+	// the ground level is reduced from the sea level pressure when missing
+	last := list.List[4]
+	if last.Main.GrndLevel != 898 || last.Main.PressureChange == nil || *last.Main.PressureChange != 1.7 || last.Main.PressureTendency != PressureRising {
+		t.Errorf("04:00 ground level %d, change %v, tendency %q", last.Main.GrndLevel, last.Main.PressureChange, last.Main.PressureTendency)
+	}
+
+	if _, ok := last.sources["main.grnd_level"]; ok || last.approximated["main.grnd_level"] != ResolutionHourly {
+		t.Errorf("reduced ground level sources %v, approximated %v", last.sources, last.approximated)
+	}
+
+	// and is kept in nullable output, with its provenance marked
+	if got := last.Provenance["main.grnd_level"]; got.Resolution != ResolutionHourly || !got.Approximated {
+		t.Errorf("reduced ground level provenance %+v", got)
+	}
+
+	data, err = json.Marshal(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"grnd_level":898`) {
+		t.Errorf("reduced ground level nulled: %s", data)
+	}
+}
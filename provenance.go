@@ -0,0 +1,60 @@
+package open_meteo_parser
+
+import (
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+// FieldProvenance records where a forecast field came from: the series
+// resolution, the sample's unix time and its offset in seconds from the
+// requested time. Approximated marks a value the parser computed in place of
+// a missing upstream one, from inputs of that resolution.
+type FieldProvenance struct {
+	Resolution   Resolution `json:"resolution"`
+	Dt           int        `json:"dt"`
+	Offset       int        `json:"offset"`
+	Approximated bool       `json:"approximated,omitempty"`
+}
+
+// SetProvenance controls whether forecasts carry a provenance block keyed by
+// the OpenWeather JSON path of each populated field.
+func (p *Parser) SetProvenance(enabled bool) *Parser {
+	p.provenance = enabled
+	return p
+}
+
+func provenance(sources, approximated fieldSources, nf pom.NearestForecast, requested time.Time) map[string]FieldProvenance {
+	times := map[Resolution]time.Time{}
+	if nf.Minutely15Forecast != nil {
+		times[ResolutionMinutely15] = nf.Minutely15Forecast.Time.Time
+	}
+	if nf.HourlyForecast != nil {
+		times[ResolutionHourly] = nf.HourlyForecast.Time.Time
+	}
+	if nf.DailyForecast != nil {
+		times[ResolutionDaily] = nf.DailyForecast.Time.Time
+	}
+
+	at := func(res Resolution) FieldProvenance {
+		sample := times[res]
+		return FieldProvenance{
+			Resolution: res,
+			Dt:         int(sample.Unix()),
+			Offset:     int(sample.Sub(requested) / time.Second),
+		}
+	}
+
+	out := make(map[string]FieldProvenance, len(sources)+len(approximated))
+	for field, res := range approximated {
+		fp := at(res)
+		fp.Approximated = true
+		out[field] = fp
+	}
+
+	for field, res := range sources {
+		out[field] = at(res)
+	}
+
+	return out
+}
@@ -0,0 +1,61 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParser_SetProvenance(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 1, 10, 0, 0, time.UTC)
+
+	p := NewParser("xxx", "https://ddd.cloudfront.net")
+	p.now = fakeNow
+	p.om = &fakeOpenMeteo{}
+
+	forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if forecast.Provenance != nil {
+		t.Errorf("provenance set without SetProvenance: %v", forecast.Provenance)
+	}
+
+	forecast, err = p.SetProvenance(true).GetOpenWeatherForecast(-8.68, 115.2, startTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hour := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	want := map[string]FieldProvenance{
+		"main.temp":     {Resolution: ResolutionHourly, Dt: int(hour.Unix()), Offset: -600},
+		"main.pressure": {Resolution: ResolutionHourly, Dt: int(hour.Unix()), Offset: -600},
+		"main.temp_max": {Resolution: ResolutionDaily, Dt: int(day.Unix()), Offset: -4200},
+
+		// approximated from the hourly temperature and wind
+		"main.feels_like": {Resolution: ResolutionHourly, Dt: int(hour.Unix()), Offset: -600, Approximated: true},
+	}
+
+	for field, w := range want {
+		if got := forecast.Provenance[field]; got != w {
+			t.Errorf("provenance[%s] = %+v, want %+v", field, got, w)
+		}
+	}
+
+	if _, ok := forecast.Provenance["visibility"]; ok {
+		t.Error("provenance recorded for a field that was not populated")
+	}
+
+	data, err := json.Marshal(forecast)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"main.temp":{"resolution":"hourly","dt":1714525200,"offset":-600}`) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
@@ -0,0 +1,264 @@
+package open_meteo_parser
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+const (
+	defaultForecastDays = 7
+	weightVariables     = 10
+	weightDays          = 14
+)
+
+type QuotaMode int
+
+const (
+	// QuotaBlock waits until the quota has room, up to MaxWait.
+	QuotaBlock QuotaMode = iota
+	// QuotaFailFast returns ErrQuotaExceeded immediately.
+	QuotaFailFast
+)
+
+// QuotaLimits caps weighted Open-Meteo calls per minute, hour and day. A
+// zero limit leaves that window unlimited.
+type QuotaLimits struct {
+	PerMinute float64
+	PerHour   float64
+	PerDay    float64
+	Mode      QuotaMode
+	MaxWait   time.Duration
+}
+
+// FreeTierQuota returns the limits of Open-Meteo's free, non-commercial API.
+func FreeTierQuota() QuotaLimits {
+	return QuotaLimits{
+		PerMinute: 600,
+		PerHour:   5000,
+		PerDay:    10000,
+		Mode:      QuotaBlock,
+		MaxWait:   time.Minute,
+	}
+}
+
+type QuotaWindowUsage struct {
+	Used  float64
+	Limit float64
+}
+
+// QuotaUsage reports weighted calls in each window, plus the total charged
+// since the quota was set.
+type QuotaUsage struct {
+	Minute QuotaWindowUsage
+	Hour   QuotaWindowUsage
+	Day    QuotaWindowUsage
+	Total  float64
+}
+
+// SetQuota enables client-side rate limiting of upstream calls.
+func (p *Parser) SetQuota(limits QuotaLimits) *Parser {
+	p.limiter = newRateLimiter(limits)
+	return p
+}
+
+// QuotaUsage returns the current quota usage, or the zero value when no
+// quota is set.
+func (p Parser) QuotaUsage() QuotaUsage {
+	if p.limiter == nil {
+		return QuotaUsage{}
+	}
+
+	return p.limiter.usage()
+}
+
+// requestWeight applies Open-Meteo's fractional call counting: a request
+// costs one call per location, scaled up for more than 10 variables and
+// for more than 14 days of data.
+func requestWeight(query url.Values) float64 {
+	return membersRequestWeight(query, 1)
+}
+
+// membersRequestWeight is requestWeight for an ensemble request, whose
+// variables count once for each of its members.
+func membersRequestWeight(query url.Values, members int) float64 {
+	variables := 0
+	for _, key := range []string{"current", "hourly", "minutely_15", "daily"} {
+		if v := query.Get(key); v != "" {
+			variables += len(strings.Split(v, ","))
+		}
+	}
+	variables *= members
+
+	days := defaultForecastDays
+	if v, err := strconv.Atoi(query.Get("forecast_days")); err == nil {
+		days = v
+	}
+	if v, err := strconv.Atoi(query.Get("past_days")); err == nil {
+		days += v
+	}
+
+	// archive requests cover a date range instead
+	start, startErr := time.Parse("2006-01-02", query.Get("start_date"))
+	end, endErr := time.Parse("2006-01-02", query.Get("end_date"))
+	if startErr == nil && endErr == nil && !end.Before(start) {
+		days = int(end.Sub(start).Hours()/24) + 1
+	}
+
+	locations := 1
+	if v := query.Get("latitude"); v != "" {
+		locations = len(strings.Split(v, ","))
+	}
+
+	return float64(locations) *
+		math.Max(1, float64(variables)/weightVariables) *
+		math.Max(1, float64(days)/weightDays)
+}
+
+func paramsWeight(params pom.IForecastParams) float64 {
+	query, err := url.ParseQuery(params.GetParams())
+	if err != nil {
+		return 1
+	}
+
+	return requestWeight(query)
+}
+
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	rate     float64 // tokens per second
+}
+
+func newTokenBucket(limit float64, window time.Duration) *tokenBucket {
+	if limit <= 0 {
+		return nil
+	}
+
+	return &tokenBucket{capacity: limit, tokens: limit, rate: limit / window.Seconds()}
+}
+
+func (b *tokenBucket) refill(elapsed time.Duration) {
+	b.tokens = math.Min(b.capacity, b.tokens+elapsed.Seconds()*b.rate)
+}
+
+func (b *tokenBucket) wait(weight float64) time.Duration {
+	if b.tokens >= weight {
+		return 0
+	}
+
+	return time.Duration((weight - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) usage() QuotaWindowUsage {
+	if b == nil {
+		return QuotaWindowUsage{}
+	}
+
+	return QuotaWindowUsage{Used: b.capacity - b.tokens, Limit: b.capacity}
+}
+
+type rateLimiter struct {
+	mu      sync.Mutex
+	limits  QuotaLimits
+	buckets []*tokenBucket
+	minute  *tokenBucket
+	hour    *tokenBucket
+	day     *tokenBucket
+	total   float64
+	last    time.Time
+	now     func() time.Time
+	sleep   func(context.Context, time.Duration) error
+}
+
+func newRateLimiter(limits QuotaLimits) *rateLimiter {
+	l := &rateLimiter{
+		limits: limits,
+		minute: newTokenBucket(limits.PerMinute, time.Minute),
+		hour:   newTokenBucket(limits.PerHour, time.Hour),
+		day:    newTokenBucket(limits.PerDay, 24*time.Hour),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+
+	for _, b := range []*tokenBucket{l.minute, l.hour, l.day} {
+		if b != nil {
+			l.buckets = append(l.buckets, b)
+		}
+	}
+
+	l.last = l.now()
+
+	return l
+}
+
+func (l *rateLimiter) refill() {
+	now := l.now()
+	elapsed := now.Sub(l.last)
+	l.last = now
+
+	for _, b := range l.buckets {
+		b.refill(elapsed)
+	}
+}
+
+// take charges weight against every window, waiting for room in QuotaBlock
+// mode until ctx is done.
+func (l *rateLimiter) take(ctx context.Context, weight float64) error {
+	for {
+		l.mu.Lock()
+		l.refill()
+
+		var wait time.Duration
+		for _, b := range l.buckets {
+			if weight > b.capacity {
+				l.mu.Unlock()
+				return fmt.Errorf("%w: request weight %.1f exceeds limit %.0f", ErrQuotaExceeded, weight, b.capacity)
+			}
+
+			if w := b.wait(weight); w > wait {
+				wait = w
+			}
+		}
+
+		if wait == 0 {
+			for _, b := range l.buckets {
+				b.tokens -= weight
+			}
+			l.total += weight
+			l.mu.Unlock()
+			return nil
+		}
+
+		l.mu.Unlock()
+
+		if l.limits.Mode == QuotaFailFast || (l.limits.MaxWait > 0 && wait > l.limits.MaxWait) {
+			return fmt.Errorf("%w: retry in %s", ErrQuotaExceeded, wait.Round(time.Millisecond))
+		}
+
+		if err := l.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *rateLimiter) usage() QuotaUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+
+	return QuotaUsage{
+		Minute: l.minute.usage(),
+		Hour:   l.hour.usage(),
+		Day:    l.day.usage(),
+		Total:  l.total,
+	}
+}
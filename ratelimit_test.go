@@ -0,0 +1,106 @@
+package open_meteo_parser
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRequestWeight(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  float64
+	}{
+		{"Test few variables", "latitude=1&longitude=2&hourly=temperature_2m,rain", 1},
+		{"Test many variables", "latitude=1&longitude=2&hourly=a,b,c,d,e,f,g,h,i,j,k,l,m,n,o", 1.5},
+		{"Test long range", "latitude=1&longitude=2&hourly=a&forecast_days=16&past_days=12", 2},
+		{"Test locations", "latitude=1,2,3&longitude=4,5,6&hourly=a", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := requestWeight(query); got != tt.want {
+				t.Errorf("requestWeight = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	params, _ := NewParser("xxx", "").forecastParams(-8.68, 115.2)
+	if got := paramsWeight(params); got != 3 {
+		t.Errorf("GenerateParams weight = %v, want 3", got)
+	}
+}
+
+func TestParser_Quota(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	t.Run("Test fail fast", func(t *testing.T) {
+		om := &fakeOpenMeteo{}
+		p := NewParser("xxx", "").SetQuota(QuotaLimits{PerMinute: 6, PerDay: 100, Mode: QuotaFailFast})
+		p.now = fakeNow
+		p.om = om
+
+		now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		p.limiter.now = func() time.Time { return now }
+		p.limiter.last = now
+
+		for i := 0; i < 2; i++ {
+			if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); err != nil {
+				t.Fatalf("call %d: %v", i, err)
+			}
+		}
+
+		_, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime)
+		if !errors.Is(err, ErrQuotaExceeded) {
+			t.Fatalf("err = %v, want ErrQuotaExceeded", err)
+		}
+
+		if calls, _ := om.calls(); calls != 2 {
+			t.Errorf("got %d upstream calls, want 2", calls)
+		}
+
+		usage := p.QuotaUsage()
+		if usage.Minute.Used != 6 || usage.Minute.Limit != 6 || usage.Day.Used != 6 || usage.Total != 6 {
+			t.Errorf("unexpected usage %+v", usage)
+		}
+
+		if usage.Hour != (QuotaWindowUsage{}) {
+			t.Errorf("unlimited window reported %+v", usage.Hour)
+		}
+	})
+
+	t.Run("Test block", func(t *testing.T) {
+		p := NewParser("xxx", "").SetQuota(QuotaLimits{PerMinute: 6, Mode: QuotaBlock, MaxWait: time.Minute})
+		p.now = fakeNow
+		p.om = &fakeOpenMeteo{}
+
+		now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		var slept time.Duration
+		p.limiter.now = func() time.Time { return now }
+		p.limiter.last = now
+		p.limiter.sleep = func(ctx context.Context, d time.Duration) error {
+			slept += d
+			now = now.Add(d)
+			return nil
+		}
+
+		for i := 0; i < 3; i++ {
+			if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); err != nil {
+				t.Fatalf("call %d: %v", i, err)
+			}
+		}
+
+		// the third call needs 3 calls at 6 per minute
+		if slept != 30*time.Second {
+			t.Errorf("slept %s, want 30s", slept)
+		}
+	})
+}
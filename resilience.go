@@ -0,0 +1,264 @@
+package open_meteo_parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RetryPolicy retries transient upstream failures: network errors, 429 and
+// 5xx responses. Delays grow exponentially from BaseDelay with full jitter,
+// capped at MaxDelay. A Retry-After longer than MaxDelay ends the retries.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// CircuitBreakerPolicy opens the circuit after FailureThreshold consecutive
+// transient failures. While open, calls fail with ErrCircuitOpen; after
+// OpenTimeout a single trial call decides whether it closes again.
+type CircuitBreakerPolicy struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+func DefaultCircuitBreakerPolicy() CircuitBreakerPolicy {
+	return CircuitBreakerPolicy{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+type resilience struct {
+	retry   RetryPolicy
+	breaker *circuitBreaker
+	sleep   func(context.Context, time.Duration) error
+	now     func() time.Time
+}
+
+func newResilience() *resilience {
+	return &resilience{
+		sleep: sleepContext,
+		now:   time.Now,
+	}
+}
+
+// SetRetryPolicy enables retries for upstream GET requests.
+func (p *Parser) SetRetryPolicy(policy RetryPolicy) *Parser {
+	if p.resilience == nil {
+		p.resilience = newResilience()
+	}
+
+	p.resilience.retry = policy
+	return p
+}
+
+// SetCircuitBreaker enables a circuit breaker shared by all upstream calls
+// made through this parser.
+func (p *Parser) SetCircuitBreaker(policy CircuitBreakerPolicy) *Parser {
+	if p.resilience == nil {
+		p.resilience = newResilience()
+	}
+
+	p.resilience.breaker = &circuitBreaker{policy: policy}
+	return p
+}
+
+// call runs an upstream request of the given Open-Meteo call weight under
+// the configured quota and resilience policy. Every attempt, including
+// retries, is charged against the quota.
+func (p Parser) call(weight float64, fn func() error) error {
+	return p.callContext(context.Background(), weight, fn)
+}
+
+// callContext is call, giving up quota waits and retry delays when ctx is
+// done. fn is expected to honour ctx itself.
+func (p Parser) callContext(ctx context.Context, weight float64, fn func() error) error {
+	attempt := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if p.limiter != nil {
+			if err := p.limiter.take(ctx, weight); err != nil {
+				return err
+			}
+		}
+		return fn()
+	}
+
+	if p.resilience == nil {
+		return attempt()
+	}
+
+	return p.resilience.do(ctx, attempt)
+}
+
+func (r *resilience) do(ctx context.Context, fn func() error) error {
+	attempts := r.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay, ok := r.retryDelay(attempt, err)
+			if !ok {
+				break
+			}
+			if sleepErr := r.sleep(ctx, delay); sleepErr != nil {
+				return sleepErr
+			}
+		}
+
+		if r.breaker != nil {
+			if openErr := r.breaker.allow(r.now()); openErr != nil {
+				return openErr
+			}
+		}
+
+		err = fn()
+
+		// the request never reached Open-Meteo or was abandoned, so it
+		// neither counts as a failure nor settles a trial
+		if errors.Is(err, ErrQuotaExceeded) || isCanceled(err) {
+			if r.breaker != nil {
+				r.breaker.cancelTrial()
+			}
+			return err
+		}
+
+		if r.breaker != nil {
+			r.breaker.record(err == nil || !isTransient(err), r.now())
+		}
+
+		if err == nil || !isTransient(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (r *resilience) retryDelay(attempt int, err error) (time.Duration, bool) {
+	var upstream *UpstreamError
+	if errors.As(err, &upstream) && upstream.RetryAfter > 0 {
+		if r.retry.MaxDelay > 0 && upstream.RetryAfter > r.retry.MaxDelay {
+			return 0, false
+		}
+		return upstream.RetryAfter, true
+	}
+
+	backoff := r.retry.BaseDelay << (attempt - 1)
+	if r.retry.MaxDelay > 0 && (backoff > r.retry.MaxDelay || backoff <= 0) {
+		backoff = r.retry.MaxDelay
+	}
+
+	if backoff <= 0 {
+		return 0, true
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1)), true
+}
+
+// isTransient reports whether err is worth retrying: network failures,
+// rate limiting and upstream server errors.
+func isTransient(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) || isCanceled(err) {
+		return false
+	}
+
+	var upstream *UpstreamError
+	if errors.As(err, &upstream) {
+		return upstream.StatusCode == http.StatusTooManyRequests || upstream.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isCanceled reports whether err comes from a done context rather than from
+// upstream. context.DeadlineExceeded is also a net.Error, so it is checked
+// before network failures.
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// sleepContext waits for d, returning early with ctx's error when ctx is
+// done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type circuitBreaker struct {
+	mu        sync.Mutex
+	policy    CircuitBreakerPolicy
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func (b *circuitBreaker) allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return nil
+	}
+
+	if now.Before(b.openUntil) || b.trial {
+		return fmt.Errorf("%w until %s", ErrCircuitOpen, b.openUntil.Format(time.RFC3339))
+	}
+
+	b.trial = true
+	return nil
+}
+
+// cancelTrial gives up a trial call that never reached Open-Meteo, so the
+// next call may try instead.
+func (b *circuitBreaker) cancelTrial() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+func (b *circuitBreaker) record(success bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		b.trial = false
+		return
+	}
+
+	b.failures++
+	if b.trial || b.failures >= b.policy.FailureThreshold {
+		b.openUntil = now.Add(b.policy.OpenTimeout)
+		b.trial = false
+	}
+}
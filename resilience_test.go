@@ -0,0 +1,200 @@
+package open_meteo_parser
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParser_RetryPolicy(t *testing.T) {
+	serverErr := &UpstreamError{StatusCode: 503}
+
+	tests := []struct {
+		name       string
+		errs       []error
+		wantCalls  int
+		wantErr    error
+		wantSleeps []time.Duration
+	}{
+		{
+			name:      "Test recovers after transient failures",
+			errs:      []error{serverErr, serverErr},
+			wantCalls: 3,
+		},
+		{
+			name:      "Test gives up after max attempts",
+			errs:      []error{serverErr, serverErr, serverErr, serverErr},
+			wantCalls: 3,
+			wantErr:   serverErr,
+		},
+		{
+			name:      "Test client error is not retried",
+			errs:      []error{&UpstreamError{StatusCode: 400, Reason: "Latitude must be in range"}},
+			wantCalls: 1,
+			wantErr:   ErrInvalidCoordinates,
+		},
+		{
+			name:       "Test honors Retry-After",
+			errs:       []error{&UpstreamError{StatusCode: 429, RetryAfter: 2 * time.Second}},
+			wantCalls:  2,
+			wantSleeps: []time.Duration{2 * time.Second},
+		},
+		{
+			name:      "Test Retry-After beyond max delay",
+			errs:      []error{&UpstreamError{StatusCode: 429, RetryAfter: time.Minute}},
+			wantCalls: 1,
+			wantErr:   ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			om := &fakeOpenMeteo{forecastErrs: tt.errs}
+			p := NewParser("xxx", "https://ddd.cloudfront.net").SetRetryPolicy(DefaultRetryPolicy())
+			p.now = fakeNow
+			p.om = om
+
+			var sleeps []time.Duration
+			p.resilience.sleep = func(ctx context.Context, d time.Duration) error {
+				sleeps = append(sleeps, d)
+				return nil
+			}
+
+			_, err := p.GetOpenWeatherForecast(-8.68, 115.2, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC))
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if calls, _ := om.calls(); calls != tt.wantCalls {
+				t.Errorf("got %d upstream calls, want %d", calls, tt.wantCalls)
+			}
+
+			for i, want := range tt.wantSleeps {
+				if i >= len(sleeps) || sleeps[i] != want {
+					t.Errorf("sleeps = %v, want %v", sleeps, tt.wantSleeps)
+					break
+				}
+			}
+
+			for _, d := range sleeps {
+				if d > DefaultRetryPolicy().MaxDelay {
+					t.Errorf("slept %s, longer than max delay", d)
+				}
+			}
+		})
+	}
+}
+
+func TestParser_CircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	om := &fakeOpenMeteo{forecastErr: &UpstreamError{StatusCode: http.StatusBadGateway}}
+	p := NewParser("xxx", "https://ddd.cloudfront.net").
+		SetCircuitBreaker(CircuitBreakerPolicy{FailureThreshold: 2, OpenTimeout: time.Minute})
+	p.now = fakeNow
+	p.om = om
+	p.resilience.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: circuit opened too early", i)
+		}
+	}
+
+	if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+
+	if calls, _ := om.calls(); calls != 2 {
+		t.Fatalf("got %d upstream calls while open, want 2", calls)
+	}
+
+	if cod := ToOpenWeatherError(ErrCircuitOpen).Cod; cod != "503" {
+		t.Errorf("Cod = %s, want 503", cod)
+	}
+
+	now = now.Add(2 * time.Minute)
+	om.mu.Lock()
+	om.forecastErr = nil
+	om.mu.Unlock()
+
+	if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); err != nil {
+		t.Fatalf("trial call failed: %v", err)
+	}
+
+	if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); err != nil {
+		t.Fatalf("circuit did not close: %v", err)
+	}
+}
+
+func TestParser_CircuitBreakerQuotaDuringTrial(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	om := &fakeOpenMeteo{forecastErr: &UpstreamError{StatusCode: http.StatusBadGateway}}
+	p := NewParser("xxx", "https://ddd.cloudfront.net").
+		SetCircuitBreaker(CircuitBreakerPolicy{FailureThreshold: 1, OpenTimeout: time.Minute})
+	p.now = fakeNow
+	p.om = om
+	p.resilience.now = func() time.Time { return now }
+
+	if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("circuit opened before the first failure")
+	}
+
+	// half-open, with the quota spent
+	now = now.Add(2 * time.Minute)
+	om.mu.Lock()
+	om.forecastErr = nil
+	om.mu.Unlock()
+
+	p.SetQuota(QuotaLimits{PerMinute: 6, Mode: QuotaFailFast})
+	limiterNow := now
+	p.limiter.now = func() time.Time { return limiterNow }
+	p.limiter.last = limiterNow
+	if err := p.limiter.take(context.Background(), 6); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("err = %v, want ErrQuotaExceeded", err)
+	}
+
+	limiterNow = limiterNow.Add(time.Minute)
+
+	if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); err != nil {
+		t.Fatalf("trial call after the quota refilled failed: %v", err)
+	}
+
+	if _, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime); err != nil {
+		t.Fatalf("circuit did not close: %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"Wed, 01 May 2024 00:00:30 GMT", 30 * time.Second},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package open_meteo_parser
+
+import (
+	"fmt"
+	"sync"
+)
+
+// flightGroup deduplicates concurrent calls with the same key: callers
+// arriving while a call is in flight wait for it and share its result.
+// When the call panics, the waiting callers get an error and the panic
+// carries on in the caller that ran it. A nil group runs every call.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg   sync.WaitGroup
+	val  any
+	err  error
+	dups int
+}
+
+func (g *flightGroup) do(key string, fn func() (any, error)) (any, error) {
+	if g == nil {
+		return fn()
+	}
+
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+
+	c := &flightCall{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	returned := false
+	defer func() {
+		var r any
+		if !returned {
+			r = recover()
+			c.val, c.err = nil, fmt.Errorf("coalesced request did not complete: %v", r)
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	c.val, c.err = fn()
+	returned = true
+
+	return c.val, c.err
+}
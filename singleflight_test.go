@@ -0,0 +1,161 @@
+package open_meteo_parser
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// inFlight reports whether a call for key is in flight.
+func (g *flightGroup) inFlight(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	_, ok := g.calls[key]
+	return ok
+}
+
+// waiting returns how many callers are waiting on the in-flight call for key.
+func (g *flightGroup) waiting(key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if c, ok := g.calls[key]; ok {
+		return c.dups
+	}
+
+	return 0
+}
+
+func TestParser_CoalescesConcurrentRequests(t *testing.T) {
+	const callers = 32
+
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		key  func(p *Parser) string
+		call func(p *Parser) error
+	}{
+		{
+			name: "Test forecast",
+			key: func(p *Parser) string {
+				params, _ := p.forecastParams(-8.68, 115.2)
+				return p.config.GetForecastURL() + "?" + params.GetParams()
+			},
+			call: func(p *Parser) error {
+				_, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime)
+				return err
+			},
+		},
+		{
+			name: "Test AQI",
+			key: func(p *Parser) string {
+				return p.config.GetAirQualityURL() + "?" + generateAQIParam(-8.68, 115.2).GetParams()
+			},
+			call: func(p *Parser) error {
+				_, err := p.GetOpenWeatherAQI(-8.68, 115.2, startTime)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			om := &fakeOpenMeteo{gate: make(chan struct{})}
+			p := NewParser("xxx", "https://ddd.cloudfront.net")
+			p.now = fakeNow
+			p.om = om
+
+			errs := make(chan error, callers)
+			var wg sync.WaitGroup
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- tt.call(p)
+				}()
+			}
+
+			key := tt.key(p)
+			deadline := time.Now().Add(5 * time.Second)
+			for p.flights.waiting(key) < callers-1 {
+				if time.Now().After(deadline) {
+					t.Fatalf("only %d callers joined the flight", p.flights.waiting(key))
+				}
+				runtime.Gosched()
+			}
+
+			close(om.gate)
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			forecastCalls, aqiCalls := om.calls()
+			if forecastCalls+aqiCalls != 1 {
+				t.Errorf("got %d upstream calls, want 1", forecastCalls+aqiCalls)
+			}
+		})
+	}
+}
+
+func TestFlightGroup_Panic(t *testing.T) {
+	g := &flightGroup{}
+	release := make(chan struct{})
+
+	leader := make(chan any)
+	go func() {
+		defer func() { leader <- recover() }()
+		g.do("key", func() (any, error) {
+			<-release
+			panic("boom")
+		})
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !g.inFlight("key") {
+		if time.Now().After(deadline) {
+			t.Fatal("the leader never started")
+		}
+		runtime.Gosched()
+	}
+
+	waiter := make(chan error)
+	go func() {
+		val, err := g.do("key", func() (any, error) { return "ran", nil })
+		if val != nil {
+			err = nil
+		}
+		waiter <- err
+	}()
+
+	for g.waiting("key") < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("the waiter never joined the flight")
+		}
+		runtime.Gosched()
+	}
+
+	close(release)
+
+	// the panic carries on in the leader, and the waiter gets an error
+	// instead of a nil result
+	if r := <-leader; r != "boom" {
+		t.Errorf("leader recovered %v, want boom", r)
+	}
+
+	if err := <-waiter; err == nil {
+		t.Error("expected an error for the waiter")
+	}
+
+	// the key is free again
+	if val, err := g.do("key", func() (any, error) { return "ran", nil }); val != "ran" || err != nil {
+		t.Errorf("got %v, %v after the panic", val, err)
+	}
+}
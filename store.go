@@ -0,0 +1,275 @@
+package open_meteo_parser
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+var (
+	ErrLocationNotRegistered = errors.New("location not registered")
+	ErrForecastUnavailable   = errors.New("forecast not yet available")
+)
+
+// UpdateSchedule describes when new model runs become available upstream:
+// every Interval from midnight UTC, Delay after the run time. Refreshes are
+// aligned to those times. A failed refresh is retried after RetryInterval.
+type UpdateSchedule struct {
+	Interval      time.Duration
+	Delay         time.Duration
+	RetryInterval time.Duration
+}
+
+func DefaultUpdateSchedule() UpdateSchedule {
+	return UpdateSchedule{
+		Interval:      3 * time.Hour,
+		Delay:         time.Hour,
+		RetryInterval: 5 * time.Minute,
+	}
+}
+
+// latest returns the most recent update time at or before now.
+func (s UpdateSchedule) latest(now time.Time) time.Time {
+	return now.Add(-s.Delay).Truncate(s.Interval).Add(s.Delay)
+}
+
+// StoredForecast is a forecast served from a ForecastStore. Stale is set when
+// the data predates the latest model update because refreshing failed;
+// LastError then holds the failure.
+type StoredForecast struct {
+	Forecast  *Forecast
+	UpdatedAt time.Time
+	Stale     bool
+	LastError error
+}
+
+// ForecastStore keeps the latest Open-Meteo data for registered locations so
+// forecasts can be served without waiting on upstream. Data is refreshed by
+// Run and kept, marked stale, when a refresh fails.
+type ForecastStore struct {
+	parser   *Parser
+	schedule UpdateSchedule
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	entries map[storeKey]*storeEntry
+	order   []storeKey
+}
+
+type storeKey struct {
+	lat, lon float64
+}
+
+type storeEntry struct {
+	resp      *pom.ForecastResponse
+	place     *Place
+	updatedAt time.Time
+	err       error
+}
+
+func NewForecastStore(p *Parser) *ForecastStore {
+	return &ForecastStore{
+		parser:   p,
+		schedule: DefaultUpdateSchedule(),
+		now:      time.Now,
+		entries:  make(map[storeKey]*storeEntry),
+	}
+}
+
+// SetUpdateSchedule replaces the refresh schedule. Zero fields fall back to
+// the defaults.
+func (s *ForecastStore) SetUpdateSchedule(schedule UpdateSchedule) *ForecastStore {
+	def := DefaultUpdateSchedule()
+
+	if schedule.Interval <= 0 {
+		schedule.Interval = def.Interval
+	}
+
+	if schedule.Delay < 0 {
+		schedule.Delay = 0
+	}
+
+	if schedule.RetryInterval <= 0 {
+		schedule.RetryInterval = def.RetryInterval
+	}
+
+	s.schedule = schedule
+	return s
+}
+
+// Register adds a location to the store. Its data is fetched on the next
+// refresh.
+func (s *ForecastStore) Register(latitude, longitude float64) error {
+	if _, err := s.parser.forecastParams(latitude, longitude); err != nil {
+		return err
+	}
+
+	key := storeKey{lat: latitude, lon: longitude}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; !ok {
+		s.entries[key] = &storeEntry{}
+		s.order = append(s.order, key)
+	}
+
+	return nil
+}
+
+// Get returns the stored forecast for a registered location at startTime.
+// It never calls upstream.
+func (s *ForecastStore) Get(latitude, longitude float64, startTime time.Time) (*StoredForecast, error) {
+	s.mu.RLock()
+	entry, ok := s.entries[storeKey{lat: latitude, lon: longitude}]
+	var e storeEntry
+	if ok {
+		e = *entry
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrLocationNotRegistered
+	}
+
+	if e.resp == nil {
+		if e.err != nil {
+			return nil, e.err
+		}
+		return nil, ErrForecastUnavailable
+	}
+
+	forecast, err := s.parser.forecastFromResponse(e.resp, startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// the place was reverse geocoded on refresh, so Get stays off upstream
+	fillPlace(forecast, latitude, longitude, e.place)
+
+	return &StoredForecast{
+		Forecast:  forecast,
+		UpdatedAt: e.updatedAt,
+		Stale:     e.updatedAt.Before(s.schedule.latest(s.now())),
+		LastError: e.err,
+	}, nil
+}
+
+// Refresh fetches fresh data for every registered location, in chunks of the
+// parser's batch size. Locations whose fetch fails keep their previous data.
+// The first error is returned. Cancelling ctx abandons the request in flight,
+// including quota waits and retry delays, and returns ctx.Err().
+func (s *ForecastStore) Refresh(ctx context.Context) error {
+	s.mu.RLock()
+	keys := append([]storeKey(nil), s.order...)
+	s.mu.RUnlock()
+
+	chunkSize := s.parser.batch.chunkSize
+	if chunkSize < 1 {
+		chunkSize = defaultBatchChunkSize
+	}
+
+	var firstErr error
+	for start := 0; start < len(keys); start += chunkSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + chunkSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		if err := s.refreshChunk(ctx, keys[start:end]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (s *ForecastStore) refreshChunk(ctx context.Context, keys []storeKey) error {
+	lats := make([]float64, len(keys))
+	lons := make([]float64, len(keys))
+	for i, key := range keys {
+		lats[i] = key.lat
+		lons[i] = key.lon
+	}
+
+	responses, err := s.parser.fetchForecastsContext(ctx, lats, lons)
+
+	// a cancelled refresh is not a failure of the stored data
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return ctxErr
+	}
+
+	now := s.now()
+
+	places := make([]*Place, len(keys))
+	if err == nil && s.parser.geocoder != nil {
+		for i, key := range keys {
+			places[i] = s.place(key)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, key := range keys {
+		entry := s.entries[key]
+		if err != nil {
+			entry.err = err
+			continue
+		}
+
+		prepareResponse(&responses[i])
+		entry.resp = &responses[i]
+		entry.updatedAt = now
+		entry.err = nil
+		if places[i] != nil {
+			entry.place = places[i]
+		}
+	}
+
+	return err
+}
+
+// place reverse geocodes a location once; later refreshes reuse the result.
+func (s *ForecastStore) place(key storeKey) *Place {
+	s.mu.RLock()
+	place := s.entries[key].place
+	s.mu.RUnlock()
+
+	if place != nil {
+		return place
+	}
+
+	place, err := s.parser.geocoder.ReverseGeocode(key.lat, key.lon)
+	if err != nil {
+		return nil
+	}
+
+	return place
+}
+
+// Run refreshes the store immediately and then at each scheduled update
+// time until ctx is done, when it returns ctx.Err().
+func (s *ForecastStore) Run(ctx context.Context) error {
+	for {
+		wait := s.schedule.RetryInterval
+		if err := s.Refresh(ctx); err == nil {
+			wait = s.schedule.latest(s.now()).Add(s.schedule.Interval).Sub(s.now())
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package open_meteo_parser
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_http "github.com/saktibimantara/go-http"
+)
+
+func TestForecastStore(t *testing.T) {
+	var down atomic.Bool
+	g, err := NewGeoNamesGeocoder(strings.NewReader(testGeoNamesDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, caller := newFakeParser("")
+	p.SetBatchOptions(1, 1).SetReverseGeocoder(g)
+	caller.respond = func(rawURL string) (*go_http.Response, error) {
+		if down.Load() {
+			return &go_http.Response{Code: 503, Data: []byte(`{"error":true,"reason":"unavailable"}`)}, nil
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		n := len(strings.Split(u.Query().Get("latitude"), ","))
+		return &go_http.Response{Code: 200, Data: repeatJSON(fakeForecastJSON, n)}, nil
+	}
+
+	now := time.Date(2024, 5, 1, 1, 30, 0, 0, time.UTC)
+	s := NewForecastStore(p).SetUpdateSchedule(UpdateSchedule{Interval: time.Hour, Delay: 15 * time.Minute})
+	s.now = func() time.Time { return now }
+
+	startTime := time.Date(2024, 5, 1, 1, 10, 0, 0, time.UTC)
+
+	if err := s.Register(91, 115.2); !errors.Is(err, ErrInvalidCoordinates) {
+		t.Errorf("Register(91, 115.2) err = %v, want ErrInvalidCoordinates", err)
+	}
+
+	if _, err := s.Get(-8.5, 115.3, startTime); !errors.Is(err, ErrLocationNotRegistered) {
+		t.Errorf("Get unregistered err = %v, want ErrLocationNotRegistered", err)
+	}
+
+	for _, loc := range [][2]float64{{-8.68, 115.2}, {-8.5, 115.3}} {
+		if err := s.Register(loc[0], loc[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.Get(-8.68, 115.2, startTime); !errors.Is(err, ErrForecastUnavailable) {
+		t.Errorf("Get before refresh err = %v, want ErrForecastUnavailable", err)
+	}
+
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(caller.calls()); got != 2 {
+		t.Errorf("got %d upstream calls, want 2", got)
+	}
+
+	got, err := s.Get(-8.68, 115.2, startTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Stale || got.Forecast.Main.Temp != 27.5 || got.Forecast.Main.Lat != "-8.68" || !got.UpdatedAt.Equal(now) {
+		t.Errorf("unexpected fresh forecast %+v", got)
+	}
+
+	// the location is filled like any other forecast's, from the place
+	// geocoded on refresh
+	if got.Forecast.Main.Location != "Denpasar" || got.Forecast.City == nil || got.Forecast.City.Name != "Denpasar" {
+		t.Errorf("location %q, city %+v", got.Forecast.Main.Location, got.Forecast.City)
+	}
+
+	// the next model update passes while upstream is down
+	down.Store(true)
+	now = now.Add(time.Hour)
+
+	if err := s.Refresh(context.Background()); err == nil {
+		t.Fatal("expected refresh error")
+	}
+
+	got, err = s.Get(-8.68, 115.2, startTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Stale || got.LastError == nil || got.Forecast.Main.Temp != 27.5 {
+		t.Errorf("unexpected stale forecast %+v", got)
+	}
+
+	down.Store(false)
+
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := s.Get(-8.68, 115.2, startTime); got.Stale || got.LastError != nil {
+		t.Errorf("forecast still stale after refresh: %+v", got)
+	}
+}
+
+func TestUpdateSchedule_latest(t *testing.T) {
+	schedule := UpdateSchedule{Interval: 3 * time.Hour, Delay: time.Hour}
+
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{now: time.Date(2024, 5, 1, 0, 30, 0, 0, time.UTC), want: time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC)},
+		{now: time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC), want: time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)},
+		{now: time.Date(2024, 5, 1, 3, 59, 0, 0, time.UTC), want: time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)},
+		{now: time.Date(2024, 5, 1, 4, 0, 0, 0, time.UTC), want: time.Date(2024, 5, 1, 4, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := schedule.latest(tt.now); !got.Equal(tt.want) {
+			t.Errorf("latest(%s) = %s, want %s", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestForecastStore_RunStops(t *testing.T) {
+	p, caller := newFakeParser(fakeForecastJSON)
+
+	s := NewForecastStore(p)
+	if err := s.Register(-8.68, 115.2); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(caller.calls()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("store was not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop")
+	}
+}
+
+// blockingCaller is a fakeCaller whose cancellable requests hang until
+// their context is done.
+type blockingCaller struct {
+	fakeCaller
+}
+
+func (b *blockingCaller) GetContext(ctx context.Context, url string) (*go_http.Response, error) {
+	b.mu.Lock()
+	b.urls = append(b.urls, url)
+	b.mu.Unlock()
+
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestForecastStore_RefreshCancelled(t *testing.T) {
+	failing := &fakeCaller{
+		respond: func(rawURL string) (*go_http.Response, error) {
+			return &go_http.Response{Code: 503, Data: []byte(`{"error":true,"reason":"unavailable"}`)}, nil
+		},
+	}
+
+	tests := []struct {
+		name   string
+		parser func() *Parser
+	}{
+		{
+			name: "Test request in flight",
+			parser: func() *Parser {
+				p := NewParser("xxx", "https://ddd.cloudfront.net")
+				p.callApi = &blockingCaller{}
+				return p
+			},
+		},
+		{
+			name: "Test quota wait",
+			parser: func() *Parser {
+				p := NewParser("xxx", "https://ddd.cloudfront.net").
+					SetQuota(QuotaLimits{PerMinute: 100, PerDay: 100, Mode: QuotaBlock})
+				p.callApi = failing
+				if err := p.limiter.take(context.Background(), 100); err != nil {
+					t.Fatal(err)
+				}
+				return p
+			},
+		},
+		{
+			name: "Test retry delay",
+			parser: func() *Parser {
+				p := NewParser("xxx", "https://ddd.cloudfront.net").
+					SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour})
+				p.callApi = failing
+				return p
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewForecastStore(tt.parser())
+			if err := s.Register(-8.68, 115.2); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			done := make(chan error)
+			go func() { done <- s.Refresh(ctx) }()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("Refresh err = %v, want context.DeadlineExceeded", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Refresh did not stop")
+			}
+
+			// the abandoned refresh is not recorded as a failure
+			if _, err := s.Get(-8.68, 115.2, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)); !errors.Is(err, ErrForecastUnavailable) {
+				t.Errorf("Get err = %v, want ErrForecastUnavailable", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package open_meteo_parser
+
+import (
+	"time"
+	// the location's timezone is loaded by name, which must not fall back
+	// to UTC on hosts without a zoneinfo database
+	_ "time/tzdata"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+// TimezoneAuto asks Open-Meteo to resolve the timezone from the coordinates.
+const TimezoneAuto = "auto"
+
+// SetTimezone sets the timezone sent to Open-Meteo, either TimezoneAuto or an
+// IANA name such as "Asia/Makassar". An empty value requests GMT.
+func (p *Parser) SetTimezone(timezone string) *Parser {
+	p.timezone = timezone
+	return p
+}
+
+// localizeResponse reinterprets the local wall-clock times returned by
+// Open-Meteo in the response's own timezone, so hourly samples map to the
+// right instants and daily samples start at local midnight.
+func localizeResponse(resp *pom.ForecastResponse) *time.Location {
+	loc := responseLocation(resp.Timezone, resp.UTCOffsetSeconds)
+	if loc == time.UTC {
+		return loc
+	}
+
+	if resp.Hourly != nil {
+		for i := range resp.Hourly.Time {
+			resp.Hourly.Time[i].Time = inLocation(resp.Hourly.Time[i].Time, loc)
+		}
+	}
+
+	if resp.Minutely15 != nil {
+		for i := range resp.Minutely15.Time {
+			resp.Minutely15.Time[i].Time = inLocation(resp.Minutely15.Time[i].Time, loc)
+		}
+	}
+
+	if resp.Daily != nil {
+		for i := range resp.Daily.Time {
+			resp.Daily.Time[i].Time = inLocation(resp.Daily.Time[i].Time, loc)
+		}
+	}
+
+	return loc
+}
+
+func responseLocation(timezone string, utcOffsetSeconds int) *time.Location {
+	switch timezone {
+	case "", "GMT", "UTC":
+		if utcOffsetSeconds == 0 {
+			return time.UTC
+		}
+	default:
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			return loc
+		}
+	}
+
+	if utcOffsetSeconds == 0 {
+		return time.UTC
+	}
+
+	return time.FixedZone(timezone, utcOffsetSeconds)
+}
+
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// utcOffset returns the location's offset from UTC in seconds at t, which is
+// what OpenWeather reports as the city timezone.
+func utcOffset(t *time.Time, loc *time.Location) int {
+	_, offset := t.In(loc).Zone()
+	return offset
+}
+
+// parseLocalUnix parses an Open-Meteo local timestamp such as daily sunrise
+// in loc, returning 0 when it is missing or malformed.
+func parseLocalUnix(value *string, loc *time.Location) int {
+	if value == nil {
+		return 0
+	}
+
+	t, err := time.ParseInLocation("2006-01-02T15:04", *value, loc)
+	if err != nil {
+		return 0
+	}
+
+	return int(t.Unix())
+}
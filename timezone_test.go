@@ -0,0 +1,95 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+func TestForecastFromResponse_Timezone(t *testing.T) {
+	tests := []struct {
+		name         string
+		timezone     string
+		startTime    time.Time
+		wantDtTxt    string
+		wantTimezone int
+	}{
+		{
+			name:         "Test GMT response",
+			timezone:     "GMT",
+			startTime:    time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC),
+			wantDtTxt:    "2024-05-01 01:00:00",
+			wantTimezone: 0,
+		},
+		{
+			name:         "Test local response",
+			timezone:     "Asia/Makassar",
+			startTime:    time.Date(2024, 4, 30, 17, 0, 0, 0, time.UTC),
+			wantDtTxt:    "2024-04-30 17:00:00",
+			wantTimezone: 8 * 60 * 60,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp pom.ForecastResponse
+			data := strings.Replace(fakeForecastJSON, `"timezone": "GMT"`, `"timezone": "`+tt.timezone+`"`, 1)
+			if err := json.Unmarshal([]byte(data), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			prepareResponse(&resp)
+			forecast, err := Parser{}.forecastFromResponse(&resp, tt.startTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if forecast.DtTxt != tt.wantDtTxt {
+				t.Errorf("DtTxt = %q, want %q", forecast.DtTxt, tt.wantDtTxt)
+			}
+
+			if forecast.Dt != int(tt.startTime.Unix()) {
+				t.Errorf("Dt = %d, want %d", forecast.Dt, tt.startTime.Unix())
+			}
+
+			if forecast.City == nil || forecast.City.Timezone != tt.wantTimezone {
+				t.Errorf("City = %+v, want timezone %d", forecast.City, tt.wantTimezone)
+			}
+
+			out, err := json.Marshal(forecast)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Contains(string(out), `"city"`) {
+				t.Errorf("city serialized on the forecast: %s", out)
+			}
+
+			if current := NewCurrentWeather(forecast); current.Timezone != tt.wantTimezone {
+				t.Errorf("CurrentWeather timezone = %d, want %d", current.Timezone, tt.wantTimezone)
+			}
+		})
+	}
+}
+
+func TestDecodeForecastResponse_UTCOffset(t *testing.T) {
+	data := strings.Replace(fakeForecastJSON, `"timezone": "GMT",
+	"utc_offset_seconds": 0`, `"timezone": "WITA",
+	"utc_offset_seconds": 28800`, 1)
+
+	responses, err := decodeForecastResponses([]byte("[" + data + "," + data + "]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a timezone the database does not know falls back to the offset
+	for _, resp := range responses {
+		loc := responseLocation(resp.Timezone, resp.UTCOffsetSeconds)
+		if now := fakeNow(); utcOffset(&now, loc) != 28800 {
+			t.Errorf("offset %d, want 28800", utcOffset(&now, loc))
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package open_meteo_parser
+
+import "fmt"
+
+// Units is an OpenWeather unit system. Parsed forecasts carry Open-Meteo's
+// native units: °C, km/h, hPa and mm.
+type Units string
+
+const (
+	UnitsStandard Units = "standard"
+	UnitsMetric   Units = "metric"
+	UnitsImperial Units = "imperial"
+)
+
+// ParseUnits parses an OpenWeather units parameter. An empty value means
+// standard, as it does for OpenWeather.
+func ParseUnits(s string) (Units, error) {
+	switch Units(s) {
+	case "":
+		return UnitsStandard, nil
+	case UnitsStandard, UnitsMetric, UnitsImperial:
+		return Units(s), nil
+	default:
+		return "", fmt.Errorf("unknown units %q", s)
+	}
+}
+
+// InUnits returns a copy of the forecast converted to the unit system.
+func (f Forecast) InUnits(units Units) Forecast {
+	f.Weather = append([]Weather(nil), f.Weather...)
+
+	f.Main.Temp = convertTemperature(f.Main.Temp, units)
+	f.Main.FeelsLike = convertTemperature(f.Main.FeelsLike, units)
+	f.Main.TempMin = convertTemperature(f.Main.TempMin, units)
+	f.Main.TempMax = convertTemperature(f.Main.TempMax, units)
+
+	if f.Derived != nil {
+		derived := *f.Derived
+		for field, value := range map[string]*float64{
+			"derived.dew_point":  &derived.DewPoint,
+			"derived.heat_index": &derived.HeatIndex,
+			"derived.wind_chill": &derived.WindChill,
+			"derived.humidex":    &derived.Humidex,
+			"derived.wet_bulb":   &derived.WetBulb,
+		} {
+			// a value without its inputs stays zero rather than 0 °C
+			if _, ok := f.sources[field]; ok {
+				*value = convertTemperature(*value, units)
+			}
+		}
+		f.Derived = &derived
+	}
+
+	f.Wind.Speed = convertSpeed(f.Wind.Speed, units)
+	f.Wind.Gust = convertSpeed(f.Wind.Gust, units)
+
+	return f
+}
+
+// InUnits returns a copy of the daily forecast converted to the unit system.
+func (d DailyForecast) InUnits(units Units) DailyForecast {
+	d.Weather = append([]Weather(nil), d.Weather...)
+
+	d.Temp.Day = convertTemperature(d.Temp.Day, units)
+	d.Temp.Min = convertTemperature(d.Temp.Min, units)
+	d.Temp.Max = convertTemperature(d.Temp.Max, units)
+	d.Temp.Night = convertTemperature(d.Temp.Night, units)
+	d.Temp.Eve = convertTemperature(d.Temp.Eve, units)
+	d.Temp.Morn = convertTemperature(d.Temp.Morn, units)
+
+	d.FeelsLike.Day = convertTemperature(d.FeelsLike.Day, units)
+	d.FeelsLike.Night = convertTemperature(d.FeelsLike.Night, units)
+	d.FeelsLike.Eve = convertTemperature(d.FeelsLike.Eve, units)
+	d.FeelsLike.Morn = convertTemperature(d.FeelsLike.Morn, units)
+
+	d.Speed = convertSpeed(d.Speed, units)
+	d.Gust = convertSpeed(d.Gust, units)
+
+	return d
+}
+
+func convertTemperature(celsius float64, units Units) float64 {
+	switch units {
+	case UnitsMetric:
+		return celsius
+	case UnitsImperial:
+		return celsius*9/5 + 32
+	default:
+		return celsius + 273.15
+	}
+}
+
+func convertSpeed(kmh float64, units Units) float64 {
+	switch units {
+	case UnitsImperial:
+		return kmh / 1.609344
+	default:
+		return kmh / 3.6
+	}
+}
@@ -0,0 +1,100 @@
+package open_meteo_parser
+
+import (
+	"math"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+// UV index categories, following the WHO Global Solar UV Index.
+const (
+	UVLow      = "low"
+	UVModerate = "moderate"
+	UVHigh     = "high"
+	UVVeryHigh = "very_high"
+	UVExtreme  = "extreme"
+)
+
+var uvAdvice = map[string]string{
+	UVLow:      "No protection needed. You can safely stay outside.",
+	UVModerate: "Seek shade around midday. Wear sunscreen, a hat and sunglasses.",
+	UVHigh:     "Reduce time in the sun between 10 a.m. and 4 p.m. Cover up and wear sunscreen, a hat and sunglasses.",
+	UVVeryHigh: "Minimize sun exposure between 10 a.m. and 4 p.m. Seek shade and use full protection.",
+	UVExtreme:  "Avoid being outside during midday hours. Unprotected skin can burn in minutes.",
+}
+
+// SetUVIndex controls whether forecasts carry the UV index at their time.
+// go-open-meteo does not decode the forecast API's hourly uv_index, so it is
+// read from the air quality API instead, which costs an extra request per
+// forecast, shared by a forecast list. The day's maximum is always filled
+// from the forecast.
+func (p *Parser) SetUVIndex(enabled bool) *Parser {
+	p.uvIndex = enabled
+	return p
+}
+
+// UVCategory returns the WHO category of a UV index, rounded to the nearest
+// whole number as it is reported.
+func UVCategory(uvi float64) string {
+	switch i := math.Round(uvi); {
+	case i <= 2:
+		return UVLow
+	case i <= 5:
+		return UVModerate
+	case i <= 7:
+		return UVHigh
+	case i <= 10:
+		return UVVeryHigh
+	default:
+		return UVExtreme
+	}
+}
+
+// UVAdvice returns sun protection advice for a UV index.
+func UVAdvice(uvi float64) string {
+	return uvAdvice[UVCategory(uvi)]
+}
+
+// describeUV sets the forecast's UV category and advice from the day's
+// maximum, or from the current index when there is no maximum.
+func (f *Forecast) describeUV() {
+	var level float64
+	if _, ok := f.sources["uv.max"]; ok {
+		level = f.UV.Max
+	} else if f.Uvi != nil {
+		level = *f.Uvi
+	} else {
+		return
+	}
+
+	uv := ensure(&f.UV)
+	uv.Category = UVCategory(level)
+	uv.Advice = UVAdvice(level)
+}
+
+// fillUV sets the forecast's current UV index from the air quality
+// response. Times the response does not cover leave it unset.
+func (p Parser) fillUV(forecast *Forecast, aqiResp *pom.AQIResponse, startTime time.Time) {
+	if aqiResp.Hourly == nil || len(aqiResp.Hourly.UVIndex) == 0 {
+		return
+	}
+
+	aqi, err := p.aqiFromResponse(aqiResp, startTime)
+	if err != nil {
+		return
+	}
+
+	uvi := aqi.Uvi
+	forecast.Uvi = &uvi
+	forecast.sources["uvi"] = ResolutionHourly
+	forecast.describeUV()
+
+	if forecast.Provenance != nil {
+		forecast.Provenance["uvi"] = FieldProvenance{
+			Resolution: ResolutionHourly,
+			Dt:         aqi.Dt,
+			Offset:     aqi.Dt - int(startTime.Unix()),
+		}
+	}
+}
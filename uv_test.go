@@ -0,0 +1,130 @@
+package open_meteo_parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUVCategory(t *testing.T) {
+	tests := []struct {
+		uvi  float64
+		want string
+	}{
+		{0, UVLow},
+		{2.4, UVLow},
+		{2.5, UVModerate},
+		{5, UVModerate},
+		{6.2, UVHigh},
+		{8, UVVeryHigh},
+		{10.4, UVVeryHigh},
+		{11, UVExtreme},
+	}
+
+	for _, tt := range tests {
+		if got := UVCategory(tt.uvi); got != tt.want {
+			t.Errorf("UVCategory(%v) = %q, want %q", tt.uvi, got, tt.want)
+		}
+
+		if UVAdvice(tt.uvi) == "" {
+			t.Errorf("UVAdvice(%v) is empty", tt.uvi)
+		}
+	}
+}
+
+func TestParser_UVAndSolar(t *testing.T) {
+	startTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	t.Run("Test daily maximum", func(t *testing.T) {
+		om := &fakeOpenMeteo{}
+		p := NewParser("xxx", "https://ddd.cloudfront.net")
+		p.om = om
+		p.now = fakeNow
+
+		forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := UV{Max: 9.6, Category: UVVeryHigh, Advice: UVAdvice(9.6)}
+		if forecast.UV == nil || *forecast.UV != want {
+			t.Errorf("UV = %+v, want %+v", forecast.UV, want)
+		}
+
+		if forecast.Uvi != nil {
+			t.Errorf("Uvi = %v without SetUVIndex", *forecast.Uvi)
+		}
+
+		if want := (Solar{Radiation: 0, RadiationSum: 22.4, SunshineDuration: 31500}); forecast.Solar == nil || *forecast.Solar != want {
+			t.Errorf("Solar = %+v, want %+v", forecast.Solar, want)
+		}
+
+		data, err := json.Marshal(forecast)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(string(data), `"uvi"`) {
+			t.Errorf("uvi serialized without SetUVIndex: %s", data)
+		}
+
+		if _, aqi := om.calls(); aqi != 0 {
+			t.Errorf("got %d air quality calls, want 0", aqi)
+		}
+	})
+
+	t.Run("Test current index", func(t *testing.T) {
+		om := &fakeOpenMeteo{}
+		p := NewParser("xxx", "https://ddd.cloudfront.net").SetUVIndex(true).SetProvenance(true)
+		p.om = om
+		p.now = fakeNow
+
+		forecast, err := p.GetOpenWeatherForecast(-8.68, 115.2, startTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if forecast.Uvi == nil || *forecast.Uvi != 0.5 {
+			t.Errorf("Uvi = %v, want 0.5", forecast.Uvi)
+		}
+
+		if got := forecast.Provenance["uvi"]; got.Resolution != ResolutionHourly || got.Dt != int(startTime.Unix()) {
+			t.Errorf("uvi provenance = %+v", got)
+		}
+
+		list, err := p.GetOpenWeatherForecastList(-8.68, 115.2, fakeNow(), time.Hour, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, want := range []float64{0, 0.5} {
+			if got := list.List[i].Uvi; got == nil || *got != want {
+				t.Errorf("list[%d].Uvi = %v, want %v", i, got, want)
+			}
+		}
+
+		// the air quality forecast has no sample for the last entry
+		if _, ok := list.List[2].sources["uvi"]; ok || list.List[2].Uvi != nil {
+			t.Error("unexpected uvi on the last entry")
+		}
+
+		if _, aqi := om.calls(); aqi != 2 {
+			t.Errorf("got %d air quality calls, want 2", aqi)
+		}
+	})
+
+	t.Run("Test air quality", func(t *testing.T) {
+		p := NewParser("xxx", "https://ddd.cloudfront.net")
+		p.om = &fakeOpenMeteo{}
+
+		aqi, err := p.GetOpenWeatherAQI(-8.68, 115.2, startTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if aqi.Uvi != 0.5 {
+			t.Errorf("AQI Uvi = %v, want 0.5", aqi.Uvi)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package open_meteo_parser
+
+import (
+	"math"
+	"time"
+
+	pom "github.com/saktibimantara/go-open-meteo"
+)
+
+// beaufortLimits are the upper wind speeds in m/s of Beaufort forces 0 to
+// 11; faster winds are force 12.
+var beaufortLimits = []float64{0.5, 1.5, 3.3, 5.5, 7.9, 10.7, 13.8, 17.1, 20.7, 24.4, 28.4, 32.6}
+
+var beaufortDescriptions = []string{
+	"Calm",
+	"Light air",
+	"Light breeze",
+	"Gentle breeze",
+	"Moderate breeze",
+	"Fresh breeze",
+	"Strong breeze",
+	"Near gale",
+	"Gale",
+	"Strong gale",
+	"Storm",
+	"Violent storm",
+	"Hurricane force",
+}
+
+var compassPoints = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// Beaufort returns the Beaufort force of a wind speed in km/h.
+func Beaufort(kmh float64) int {
+	ms := kmh / 3.6
+	for force, limit := range beaufortLimits {
+		if ms < limit {
+			return force
+		}
+	}
+
+	return len(beaufortLimits)
+}
+
+// BeaufortDescription returns the WMO name of a Beaufort force.
+func BeaufortDescription(force int) string {
+	if force < 0 || force >= len(beaufortDescriptions) {
+		return ""
+	}
+
+	return beaufortDescriptions[force]
+}
+
+// CompassDirection returns the 16-point compass direction, such as "NNE",
+// of a wind direction in degrees.
+func CompassDirection(deg float64) string {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+
+	return compassPoints[int((deg+11.25)/22.5)%len(compassPoints)]
+}
+
+// vectorMeanWind averages winds as vectors, so opposing directions cancel
+// and 350° and 10° average to north rather than south. Directions are where
+// the wind comes from, in degrees.
+func vectorMeanWind(speeds, degs []float64) (speed, deg float64) {
+	return weightedMeanWind(speeds, degs, nil)
+}
+
+// weightedMeanWind averages winds as vectors like vectorMeanWind, weighting
+// each by weights. Nil weights count every wind equally.
+func weightedMeanWind(speeds, degs, weights []float64) (speed, deg float64) {
+	var u, v, total float64
+	for i, s := range speeds {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+
+		rad := toRadians(degs[i])
+		u -= w * s * math.Sin(rad)
+		v -= w * s * math.Cos(rad)
+		total += w
+	}
+
+	if total == 0 {
+		return 0, 0
+	}
+
+	u /= total
+	v /= total
+
+	deg = math.Mod(math.Atan2(-u, -v)*180/math.Pi+360, 360)
+	return math.Hypot(u, v), deg
+}
+
+// describe sets the Beaufort force, its description and the compass
+// direction from the wind's speed in km/h and direction.
+func (w *Wind) describe(sources fieldSources) {
+	if _, ok := sources["wind.speed"]; ok {
+		w.Beaufort = Beaufort(w.Speed)
+		w.Description = BeaufortDescription(w.Beaufort)
+	}
+
+	if _, ok := sources["wind.deg"]; ok {
+		w.Direction = CompassDirection(float64(w.Deg))
+	}
+}
+
+// aggregateWind replaces the forecast's wind with the vector mean of the
+// samples in the step starting at t, and its gust with their maximum, and
+// derives the wind chill and feels-like temperature again from it. It uses
+// the finest series with more than one sample in the step, and leaves the
+// wind as sampled when there is none.
+func (f *Forecast) aggregateWind(resp *pom.ForecastResponse, t time.Time, step time.Duration) {
+	type series struct {
+		times            []pom.CustomTime
+		speed, deg, gust []float64
+	}
+
+	var all []series
+	if m15 := resp.Minutely15; m15 != nil {
+		all = append(all, series{m15.Time, m15.WindSpeed10m, m15.WindDirection10m, m15.WindGusts10m})
+	}
+	if hourly := resp.Hourly; hourly != nil {
+		all = append(all, series{hourly.Time, hourly.WindSpeed10m, hourly.WindDirection10m, hourly.WindGusts10m})
+	}
+
+	end := t.Add(step)
+	for _, s := range all {
+		var speeds, degs []float64
+		var gust *float64
+
+		for i, st := range s.times {
+			if st.Time.Before(t) || !st.Time.Before(end) {
+				continue
+			}
+
+			speed, deg := valueAt(s.speed, i), valueAt(s.deg, i)
+			if speed == nil || deg == nil {
+				continue
+			}
+
+			speeds = append(speeds, *speed)
+			degs = append(degs, *deg)
+
+			if g := valueAt(s.gust, i); g != nil && (gust == nil || *g > *gust) {
+				gust = g
+			}
+		}
+
+		if len(speeds) < 2 {
+			continue
+		}
+
+		speed, deg := vectorMeanWind(speeds, degs)
+		f.Wind.Speed = math.Round(speed*10) / 10
+		f.Wind.Deg = int(math.Round(deg)) % 360
+		if gust != nil {
+			f.Wind.Gust = *gust
+		}
+
+		f.Wind.describe(f.sources)
+		f.derive(f.sources)
+		return
+	}
+}
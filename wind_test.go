@@ -0,0 +1,124 @@
+package open_meteo_parser
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const fakeWindJSON = `{
+	"latitude": -8.68,
+	"longitude": 115.2,
+	"timezone": "GMT",
+	"hourly": {
+		"time": ["2024-05-01T00:00", "2024-05-01T01:00", "2024-05-01T02:00", "2024-05-01T03:00", "2024-05-01T04:00", "2024-05-01T05:00"],
+		"temperature_2m": [2.1, 2.5, 3.0, 3.2, 3.4, 3.1],
+		"wind_speed_10m": [10, 10, 20, 30, 30, 30],
+		"wind_direction_10m": [350, 10, 0, 90, 90, 90],
+		"wind_gusts_10m": [15, 25, 30, 40, 55, 45]
+	}
+}`
+
+func TestBeaufort(t *testing.T) {
+	tests := []struct {
+		kmh  float64
+		want int
+		desc string
+	}{
+		{0, 0, "Calm"},
+		{3, 1, "Light air"},
+		{19, 3, "Gentle breeze"},
+		{20, 4, "Moderate breeze"},
+		{61, 7, "Near gale"},
+		{62, 8, "Gale"},
+		{117, 11, "Violent storm"},
+		{118, 12, "Hurricane force"},
+	}
+
+	for _, tt := range tests {
+		got := Beaufort(tt.kmh)
+		if got != tt.want || BeaufortDescription(got) != tt.desc {
+			t.Errorf("Beaufort(%v) = %d %q, want %d %q", tt.kmh, got, BeaufortDescription(got), tt.want, tt.desc)
+		}
+	}
+}
+
+func TestCompassDirection(t *testing.T) {
+	tests := map[float64]string{
+		0:     "N",
+		11.24: "N",
+		11.25: "NNE",
+		45:    "NE",
+		180:   "S",
+		260:   "W",
+		348.7: "NNW",
+		349:   "N",
+		360:   "N",
+		-90:   "W",
+	}
+
+	for deg, want := range tests {
+		if got := CompassDirection(deg); got != want {
+			t.Errorf("CompassDirection(%v) = %q, want %q", deg, got, want)
+		}
+	}
+}
+
+func TestVectorMeanWind(t *testing.T) {
+	tests := []struct {
+		name      string
+		speeds    []float64
+		degs      []float64
+		speed     float64
+		deg       float64
+		tolerance float64
+	}{
+		{"across north", []float64{10, 10}, []float64{350, 10}, 9.85, 0, 0.01},
+		{"opposing", []float64{10, 10}, []float64{90, 270}, 0, -1, 1e-9},
+		{"same direction", []float64{10, 20}, []float64{225, 225}, 15, 225, 1e-9},
+	}
+
+	for _, tt := range tests {
+		speed, deg := vectorMeanWind(tt.speeds, tt.degs)
+		if math.Abs(speed-tt.speed) > tt.tolerance {
+			t.Errorf("%s: speed = %v, want %v", tt.name, speed, tt.speed)
+		}
+
+		if tt.deg >= 0 && math.Abs(math.Mod(deg-tt.deg+540, 360)-180) > 1e-6 {
+			t.Errorf("%s: deg = %v, want %v", tt.name, deg, tt.deg)
+		}
+	}
+}
+
+func TestParser_WindAggregation(t *testing.T) {
+	p, _ := newFakeParser(fakeWindJSON)
+
+	list, err := p.GetOpenWeatherForecastList(-8.68, 115.2, fakeNow(), 3*time.Hour, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 10 km/h from 350° and 10°, and 20 km/h from north
+	first := list.List[0].Wind
+	if first.Deg != 0 || first.Speed != 13.2 || first.Gust != 30 || first.Direction != "N" || first.Beaufort != 3 {
+		t.Errorf("first step wind %+v", first)
+	}
+
+	second := list.List[1].Wind
+	if second.Deg != 90 || second.Speed != 30 || second.Gust != 55 || second.Direction != "E" || second.Description != "Fresh breeze" {
+		t.Errorf("second step wind %+v", second)
+	}
+
+	// the wind chill and the feels-like temperature follow the aggregated
+	// wind rather than the sampled one
+	for _, f := range list.List {
+		want := WindChill(f.Main.Temp, f.Wind.Speed)
+		if f.Derived.WindChill != want || f.Main.FeelsLike != want {
+			t.Errorf("%s wind chill %v, feels like %v, want %v", f.DtTxt, f.Derived.WindChill, f.Main.FeelsLike, want)
+		}
+	}
+
+	if got := list.List[1].InUnits(UnitsMetric).Wind; got.Beaufort != 5 || got.Speed != 30/3.6 {
+		t.Errorf("Beaufort changed with units: %+v", got)
+	}
+}